@@ -0,0 +1,38 @@
+package okgroup
+
+import "time"
+
+// A clock tells the time and schedules timers for the package.
+//
+// It allows to drive timing dependent features, such as hedging
+// and timeouts, by a fake clock in tests. Wait also times the deadline
+// of the group's context by the clock, while the context itself
+// expires by the time package.
+type clock interface {
+	Now() time.Time
+	// NewTimer returns a channel receiving the current time once d elapses
+	// along with a function stopping the timer.
+	NewTimer(d time.Duration) (<-chan time.Time, func() bool)
+	// AfterFunc calls f in its own goroutine once d elapses
+	// and returns a function stopping the timer.
+	AfterFunc(d time.Duration, f func()) func() bool
+}
+
+// clk is the clock of new groups, tests replace it with a fake clock.
+var clk clock = realClock{}
+
+// realClock is a clock backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	t := time.NewTimer(d)
+	return t.C, t.Stop
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) func() bool {
+	return time.AfterFunc(d, f).Stop
+}
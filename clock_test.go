@@ -0,0 +1,243 @@
+package okgroup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock whose time only moves when advanced.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+	f  func()
+}
+
+func newFakeClock(t *testing.T) *fakeClock {
+	c := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	clk = c
+	t.Cleanup(func() { clk = realClock{} })
+	return c
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	t := &fakeTimer{c: make(chan time.Time, 1)}
+	return t.c, c.schedule(t, d)
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) func() bool {
+	return c.schedule(&fakeTimer{f: f}, d)
+}
+
+func (c *fakeClock) schedule(t *fakeTimer, d time.Duration) func() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t.at = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	return func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, pending := range c.timers {
+			if pending == t {
+				c.timers = append(c.timers[:i], c.timers[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Advance moves the time forward by d, firing timers which are due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		switch {
+		case t.at.After(c.now):
+			pending = append(pending, t)
+		case t.f != nil:
+			go t.f()
+		default:
+			t.c <- c.now
+		}
+	}
+	c.timers = pending
+}
+
+// BlockUntil blocks until n timers are pending.
+func (c *fakeClock) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		pending := len(c.timers)
+		c.mu.Unlock()
+		if pending >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFakeClock_GoHedge(t *testing.T) {
+	c := newFakeClock(t)
+	var calls int32
+	var mu sync.Mutex
+	g, _ := WithContext[Result](context.Background())
+	g.GoHedge(time.Hour, func(ctx context.Context) (Result, error) {
+		mu.Lock()
+		calls++
+		call := calls
+		mu.Unlock()
+		if call == 1 {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "backup", nil
+	})
+	c.BlockUntil(1)
+	c.Advance(time.Hour)
+	got, err := g.Wait()
+	if err != nil {
+		t.Fatalf("want nil err, got %v", err)
+	}
+	if got != "backup" {
+		t.Errorf("got %v, want backup", got)
+	}
+	if calls != 2 {
+		t.Errorf("got %d calls, want 2", calls)
+	}
+}
+
+func TestFakeClock_Wait(t *testing.T) {
+	c := newFakeClock(t)
+	g, _ := WithTimeout[Result](context.Background(), time.Hour)
+	release := make(chan struct{})
+	g.Go(func() (Result, error) { <-release; return "executor_1", nil })
+	done := make(chan Result)
+	go func() {
+		got, _ := g.Wait()
+		done <- got
+	}()
+	// Wait times the deadline of the group's context by the clock.
+	c.BlockUntil(1)
+	close(release)
+	if got := <-done; got != "executor_1" {
+		t.Errorf("got %v, want executor_1", got)
+	}
+}
+
+func TestFakeClock_WaitWithTimeout(t *testing.T) {
+	c := newFakeClock(t)
+	g, ctx := WithContext[Result](context.Background())
+	g.Go(func() (Result, error) { <-ctx.Done(); return "", ctx.Err() })
+	done := make(chan error)
+	go func() {
+		_, err := g.WaitWithTimeout(time.Minute)
+		done <- err
+	}()
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	if err := <-done; !errors.Is(err, ErrGroupTimeout) {
+		t.Errorf("got err %v, want err %v", err, ErrGroupTimeout)
+	}
+	g.Close()
+}
+
+func TestFakeClock_WaitUntil(t *testing.T) {
+	c := newFakeClock(t)
+	g, ctx := WithContext[Result](context.Background())
+	g.Go(func() (Result, error) { <-ctx.Done(); return "", ctx.Err() })
+	done := make(chan error)
+	go func() {
+		_, err := g.WaitUntil(c.Now().Add(time.Minute))
+		done <- err
+	}()
+	c.BlockUntil(1)
+	c.Advance(30 * time.Second)
+	select {
+	case err := <-done:
+		t.Fatalf("got err %v before the deadline", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	c.Advance(30 * time.Second)
+	if err := <-done; !errors.Is(err, ErrGroupTimeout) {
+		t.Errorf("got err %v, want err %v", err, ErrGroupTimeout)
+	}
+	g.Close()
+}
+
+func TestFakeClock_WaitSummary(t *testing.T) {
+	c := newFakeClock(t)
+	g := New[Result]()
+	g.Go(func() (Result, error) { c.Advance(time.Second); return "", errors.New("executor_1 failed") })
+	g.Go(func() (Result, error) { return "executor_2", nil })
+	got := g.WaitSummary()
+	if got.Value != "executor_2" || got.Err != nil {
+		t.Errorf("got %v, %v, want executor_2, nil", got.Value, got.Err)
+	}
+	if got.Duration != time.Second {
+		t.Errorf("got duration %v, want %v", got.Duration, time.Second)
+	}
+	if got.Submitted != 2 || got.Succeeded != 1 || got.Failed != 1 || len(got.Errors) != 1 {
+		t.Errorf("got %+v, want 2 submitted, 1 succeeded and 1 failed", got)
+	}
+}
+
+func TestFakeClock_Deadline(t *testing.T) {
+	clock := newFakeClock(t)
+	g := New[Result](WithNoCancelOnSuccess(), WithCache[Result](&mapCache{m: map[string]Result{"user": "cached"}}))
+	g.Deadline(clock.Now().Add(time.Second))
+	var mu sync.Mutex
+	var ran []Result
+	submit := func(r Result) {
+		g.Go(func() (Result, error) {
+			mu.Lock()
+			ran = append(ran, r)
+			mu.Unlock()
+			return r, nil
+		})
+	}
+	submit("executor_1")
+	clock.Advance(500 * time.Millisecond)
+	g.TryGo(func() (Result, error) { return "executor_2", nil })
+	clock.Advance(500 * time.Millisecond)
+	submit("executor_3")
+	g.GoCtx(func(ctx context.Context) (Result, error) { return "executor_4", nil })
+	g.GoBatch([]func() (Result, error){func() (Result, error) { return "executor_5", nil }})
+	if err := g.TryGo(func() (Result, error) { return "executor_6", nil }); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+	g.GoKeyed("user", func() (Result, error) { return "executor_7", nil })
+	g.GoHedge(time.Minute, func(ctx context.Context) (Result, error) {
+		mu.Lock()
+		ran = append(ran, "executor_8")
+		mu.Unlock()
+		return "executor_8", nil
+	})
+	clock.Advance(time.Minute)
+	oks, err := g.WaitAll()
+	if err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+	if len(oks) != 2 || len(ran) != 1 || ran[0] != "executor_1" {
+		t.Errorf("got %v ok responses and %v run, want executor_1 and executor_2", oks, ran)
+	}
+	if got := g.Submitted(); got != 2 {
+		t.Errorf("got %d submitted, want 2", got)
+	}
+}
@@ -0,0 +1,73 @@
+package okgroup
+
+import "context"
+
+// An ErrGroup is a collection of goroutines executing functions
+// having the signature func() error.
+//
+// It is a Group of functions without a meaningful ok response, the first
+// function returning a nil error is the ok one.
+type ErrGroup struct {
+	g *Group[struct{}]
+}
+
+// NewErrGroup returns a new ErrGroup.
+//
+// An ErrGroup created by calling NewErrGroup has no context, as a Group created by calling New.
+func NewErrGroup(opts ...Option) *ErrGroup {
+	return &ErrGroup{g: New[struct{}](opts...)}
+}
+
+// ErrGroupWithContext returns a new ErrGroup and a derived Context from a given ctx.
+//
+// The derived Context is canceled if a function passed to Go returns
+// a nil error or the first time Wait returns.
+func ErrGroupWithContext(ctx context.Context, opts ...Option) (*ErrGroup, context.Context) {
+	g, ctx := WithContext[struct{}](ctx, opts...)
+	return &ErrGroup{g: g}, ctx
+}
+
+// Go executes a given function in a new goroutine.
+func (e *ErrGroup) Go(f func() error) {
+	e.g.Go(func() (struct{}, error) {
+		return struct{}{}, f()
+	})
+}
+
+// Wait blocks until all function calls from the Go method have returned.
+//
+// If any function returned a nil error then Wait returns a nil error,
+// otherwise the group's error is returned.
+func (e *ErrGroup) Wait() error {
+	_, err := e.g.Wait()
+	return err
+}
+
+// An ErrGroupRunner executes functions having the signature func() error,
+// such as errgroup.Group from golang.org/x/sync/errgroup.
+type ErrGroupRunner interface {
+	Go(f func() error)
+}
+
+// FromErrGroup returns a new Group executing f through eg.
+//
+// It bridges codebases managing concurrency with errgroup: f is passed to eg,
+// so it is subject to eg's limit and its error is returned by eg's Wait, while
+// the returned Group's Wait returns f's result. The returned Group has no context,
+// as a Group created by calling New.
+func FromErrGroup[T any](eg ErrGroupRunner, f func() (T, error)) *Group[T] {
+	g := New[T]()
+	t := task{index: g.next()}
+	g.wg.Add(1)
+	eg.Go(func() error {
+		defer g.wg.Done()
+		var err error
+		g.do(t, func(context.Context) (T, error) {
+			var ok T
+			ok, err = f()
+			return ok, err
+		})
+		return err
+	})
+	return g
+}
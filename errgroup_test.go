@@ -0,0 +1,100 @@
+package okgroup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestErrGroup_Wait(t *testing.T) {
+	err1, err2 := errors.New("executor_1 failed"), errors.New("executor_2 failed")
+	tests := []struct {
+		name   string
+		fns    []func() error
+		errors []error
+	}{
+		{
+			name: "1 success",
+			fns: []func() error{
+				func() error { return err1 },
+				func() error { time.Sleep(time.Millisecond * 10); return nil },
+			},
+		},
+		{
+			name: "only errors",
+			fns: []func() error{
+				func() error { return err1 },
+				func() error { return err2 },
+			},
+			errors: []error{err1, err2},
+		},
+	}
+	for _, tc := range tests {
+		g, ctx := ErrGroupWithContext(context.Background())
+		for _, fn := range tc.fns {
+			g.Go(fn)
+		}
+		err := g.Wait()
+		if (err != nil) != (len(tc.errors) > 0) {
+			t.Fatalf("%s: got err %v, want errors %v", tc.name, err, tc.errors)
+		}
+		for _, wanterr := range tc.errors {
+			if !errors.Is(err, wanterr) {
+				t.Errorf("%s: got err %v, want err %v", tc.name, err, wanterr)
+			}
+		}
+		select {
+		case <-ctx.Done():
+		default:
+			t.Errorf("%s: want ctx canceled", tc.name)
+		}
+	}
+}
+
+// runner is an ErrGroupRunner collecting errors like errgroup.Group.
+type runner struct {
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *runner) Go(f func() error) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		if err := f(); err != nil {
+			r.mu.Lock()
+			r.errs = append(r.errs, err)
+			r.mu.Unlock()
+		}
+	}()
+}
+
+func TestFromErrGroup(t *testing.T) {
+	errFailed := errors.New("executor_1 failed")
+	tests := []struct {
+		ok   Result
+		err  error
+		want Result
+	}{
+		{ok: "executor_1", want: "executor_1"},
+		{err: errFailed},
+	}
+	for _, tc := range tests {
+		eg := &runner{}
+		g := FromErrGroup(eg, func() (Result, error) { return tc.ok, tc.err })
+		got, err := g.Wait()
+		if got != tc.want || !errors.Is(err, tc.err) {
+			t.Errorf("got %v, %v, want %v, %v", got, err, tc.want, tc.err)
+		}
+		eg.wg.Wait()
+		if tc.err != nil && (len(eg.errs) != 1 || eg.errs[0] != tc.err) {
+			t.Errorf("got %v errgroup errors, want %v", eg.errs, tc.err)
+		}
+		if tc.err == nil && len(eg.errs) != 0 {
+			t.Errorf("got %v errgroup errors, want none", eg.errs)
+		}
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/erni27/okgroup
 
-go 1.18
+go 1.23
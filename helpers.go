@@ -0,0 +1,372 @@
+package okgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// All executes the given functions concurrently and returns all ok responses.
+//
+// If any function fails, All returns the group's error along with
+// the ok responses of the functions which succeeded.
+func All[T any](ctx context.Context, fns ...func() (T, error)) ([]T, error) {
+	g, _ := WithContext[T](ctx)
+	for _, f := range fns {
+		g.Go(f)
+	}
+	oks, err := g.WaitAll()
+	return values(oks), err
+}
+
+// Any executes the given functions concurrently and returns the first ok response.
+//
+// If all functions fail, Any returns a T zero value along with the group's error.
+func Any[T any](ctx context.Context, fns ...func() (T, error)) (T, error) {
+	return Race(ctx, fns...)
+}
+
+// Race executes the given functions concurrently, the first function returning
+// an ok response wins the race and cancels the remaining ones.
+//
+// The group's context derived from ctx is always canceled before Race returns.
+// If all functions fail, Race returns a T zero value along with the group's error.
+func Race[T any](ctx context.Context, fns ...func() (T, error)) (T, error) {
+	g, _ := WithContext[T](ctx)
+	for _, f := range fns {
+		g.Go(f)
+	}
+	return g.Wait()
+}
+
+// RaceFunc is like Race but does not use a context.
+//
+// It is intended for racing functions which have nothing to cancel.
+func RaceFunc[T any](fns ...func() (T, error)) (T, error) {
+	g := New[T]()
+	for _, f := range fns {
+		g.Go(f)
+	}
+	return g.Wait()
+}
+
+// Gather executes the given functions concurrently and returns all ok responses
+// and all errors separately.
+//
+// The returned slices do not correspond positionally.
+func Gather[T any](ctx context.Context, fns ...func() (T, error)) ([]T, []error) {
+	oks, err := All(ctx, fns...)
+	if grouperr, ok := AsGroupError(err); ok {
+		return oks, grouperr.errors
+	}
+	return oks, nil
+}
+
+// Parallel executes the given functions concurrently and returns all ok responses.
+//
+// Unlike All, Parallel does not use a context, all functions run to completion.
+// If any function fails, Parallel returns the group's error along with
+// the ok responses of the functions which succeeded.
+func Parallel[T any](fns ...func() (T, error)) ([]T, error) {
+	g := New[T]()
+	for _, f := range fns {
+		g.Go(f)
+	}
+	oks, err := g.WaitAll()
+	return values(oks), err
+}
+
+// ErrNoMajority is returned by Majority and MajorityFunc if no ok response
+// is returned by more than half of the functions.
+var ErrNoMajority = errors.New("okgroup: no majority")
+
+// Majority executes the given functions concurrently and returns the ok response
+// returned by more than half of them.
+//
+// If there is no such ok response, Majority returns a T zero value along with ErrNoMajority.
+func Majority[T comparable](ctx context.Context, fns ...func() (T, error)) (T, error) {
+	return MajorityFunc(ctx, func(a, b T) bool { return a == b }, fns...)
+}
+
+// MajorityFunc is like Majority but uses the equal function to compare ok responses.
+func MajorityFunc[T any](ctx context.Context, equal func(T, T) bool, fns ...func() (T, error)) (T, error) {
+	oks, _ := All(ctx, fns...)
+	for i := range oks {
+		votes := 0
+		for j := range oks {
+			if equal(oks[i], oks[j]) {
+				votes++
+			}
+		}
+		if votes > len(fns)/2 {
+			return oks[i], nil
+		}
+	}
+	var ok T
+	return ok, ErrNoMajority
+}
+
+// Lazy wraps f, so it is executed only if ctx is not done yet.
+//
+// The returned function returns a T zero value along with ctx.Err()
+// if ctx is done before it is called, it is intended to skip functions
+// queued after the group's context was canceled.
+func Lazy[T any](ctx context.Context, f func() (T, error)) func() (T, error) {
+	return func() (T, error) {
+		if err := ctx.Err(); err != nil {
+			var ok T
+			return ok, err
+		}
+		return f()
+	}
+}
+
+// First executes f concurrently for each of the inputs and returns
+// the first input for which f returns an ok response, along with the response.
+//
+// f is passed the group's context derived from ctx, which is canceled
+// once the first ok response is returned. If f fails for all inputs,
+// First returns I and T zero values along with the group's error.
+func First[I, T any](ctx context.Context, inputs []I, f func(context.Context, I) (T, error)) (I, T, error) {
+	g, ctx := WithContext[T](ctx)
+	for i, input := range inputs {
+		g.GoWithMeta(i, func() (T, error) { return f(ctx, input) })
+	}
+	res, err := g.WaitMeta()
+	if err != nil {
+		var input I
+		return input, res.Value, err
+	}
+	return inputs[res.Meta.(int)], res.Value, nil
+}
+
+// AnyTrue executes pred concurrently for each of the inputs and reports
+// whether pred returns true for any of them.
+//
+// pred is passed the group's context derived from ctx, which is canceled
+// once pred returns true. If pred returns false for all inputs, AnyTrue
+// returns false and a nil error. If pred fails for some inputs and does not
+// return true for any, AnyTrue returns false along with the group's error.
+func AnyTrue[I any](ctx context.Context, inputs []I, pred func(context.Context, I) (bool, error)) (bool, error) {
+	if len(inputs) == 0 {
+		return false, nil
+	}
+	g, ctx := WithContext[bool](ctx, WithOK(isTrue))
+	for _, input := range inputs {
+		g.Go(func() (bool, error) { return pred(ctx, input) })
+	}
+	ok, err := g.Wait()
+	if err == nil {
+		return ok, nil
+	}
+	if grouperr, isgrouperr := AsGroupError(err); isgrouperr {
+		for _, err := range grouperr.errors {
+			if !errors.Is(err, ErrNotOK) {
+				return false, grouperr
+			}
+		}
+		return false, nil
+	}
+	return false, err
+}
+
+// AllTrue executes pred concurrently for each of the inputs and reports
+// whether pred returns true for all of them.
+//
+// pred is passed the group's context derived from ctx, which is canceled
+// as soon as pred returns false or fails. If pred returns false, AllTrue
+// returns false and a nil error, if it fails first, AllTrue returns false
+// along with the group's error.
+func AllTrue[I any](ctx context.Context, inputs []I, pred func(context.Context, I) (bool, error)) (bool, error) {
+	g, ctx := WithContext[bool](ctx, WithOK(isTrue), WithCancelOnFirstFailure(), WithNoCancelOnSuccess())
+	for _, input := range inputs {
+		g.Go(func() (bool, error) { return pred(ctx, input) })
+	}
+	_, err := g.WaitAll()
+	if err == nil {
+		return true, nil
+	}
+	if grouperr, ok := AsGroupError(err); ok && errors.Is(grouperr.Cause(), ErrNotOK) {
+		return false, nil
+	}
+	return false, err
+}
+
+// isTrue reports whether b is true.
+func isTrue(b bool) bool {
+	return b
+}
+
+// WaitUnique is like calling WaitAll on the group but returns
+// distinct ok responses in the order they were first produced.
+//
+// It is intended for racing replicas which may return identical values.
+func WaitUnique[T comparable](g *Group[T]) ([]T, error) {
+	oks, err := g.WaitAll()
+	seen := make(map[T]struct{}, len(oks))
+	unique := make([]T, 0, len(oks))
+	for _, ok := range oks {
+		if _, dup := seen[ok.Value]; dup {
+			continue
+		}
+		seen[ok.Value] = struct{}{}
+		unique = append(unique, ok.Value)
+	}
+	return unique, err
+}
+
+// Join2 waits for both groups and returns their ok responses.
+//
+// If any group fails, Join2 returns A and B zero values along with
+// an Error containing the errors of the failed groups,
+// annotated with "first group" and "second group" respectively.
+func Join2[A, B any](ga *Group[A], gb *Group[B]) (A, B, error) {
+	a, erra := ga.Wait()
+	b, errb := gb.Wait()
+	var errs []error
+	if erra != nil {
+		errs = append(errs, annotate(erra, "first group"))
+	}
+	if errb != nil {
+		errs = append(errs, annotate(errb, "second group"))
+	}
+	if len(errs) > 0 {
+		var zeroa A
+		var zerob B
+		return zeroa, zerob, Error{errors: errs}
+	}
+	return a, b, nil
+}
+
+// ForEach waits for the group, calling fn for each ok response as soon as
+// it is returned. It blocks until all functions have returned.
+//
+// If the group was configured with WithResults, ok responses are received from Results,
+// otherwise from WaitStream. If fn fails, the group's context is canceled
+// with the fn's error as the cause, fn is not called again and ForEach returns the error.
+// Otherwise ForEach returns the group's error as returned by Wait.
+// Unless the group was configured with WithNoCancelOnSuccess, functions
+// are canceled once the first ok response is returned.
+func ForEach[T any](g *Group[T], fn func(T) error) error {
+	var fnErr error
+	consume := func(ok T) {
+		if fnErr != nil {
+			return
+		}
+		if fnErr = fn(ok); fnErr != nil {
+			g.stopWith(fnErr)
+		}
+	}
+	if results := g.Results(); results != nil {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for outcome := range results {
+				if outcome.Err == nil {
+					consume(outcome.Value)
+				}
+			}
+		}()
+		g.Wait()
+		<-done
+	} else {
+		for ok := range g.WaitStream() {
+			consume(ok)
+		}
+	}
+	if fnErr != nil {
+		return fnErr
+	}
+	_, err := g.Wait()
+	return err
+}
+
+// Pipeline returns a Group composing g with the group returned by stage.
+//
+// Once g has an ok response, stage is called with it and the returned group
+// becomes the active one, Wait on the returned Group returns its result.
+// If g or stage fails, the returned Group fails with the error.
+// The returned Group's context shares g's lifetime: it is derived from g's parent Context
+// and canceled at g's deadline, if any, so a stage cannot outlive the cap set by WithTimeout.
+// stage is passed the context and should derive its group from it, so that
+// closing the returned Group cancels the stage.
+func Pipeline[T, U any](g *Group[T], stage func(context.Context, T) (*Group[U], error)) *Group[U] {
+	p := newGroup[U](g.derive(), nil)
+	p.parent, p.stopCh = g.parent, g.stopCh
+	p.GoCtx(func(ctx context.Context) (U, error) {
+		var zero U
+		ok, err := g.Wait()
+		if err != nil {
+			return zero, err
+		}
+		next, err := stage(ctx, ok)
+		if err != nil {
+			return zero, err
+		}
+		return next.Wait()
+	})
+	return p
+}
+
+// Coalesce returns a Group merging the results of the groups.
+//
+// Wait on the returned Group blocks until all groups have returned
+// and returns the first ok response of any of them. If all groups fail,
+// the returned Group's error contains their errors, attributed
+// to the groups' positions. Wait must not be called on the groups directly,
+// the returned Group waits for them.
+func Coalesce[T any](groups ...*Group[T]) *Group[T] {
+	c := New[T]()
+	for _, g := range groups {
+		c.Go(g.Wait)
+	}
+	return c
+}
+
+// CombineErrors returns an Error containing errs, replacing each error
+// whose chain contains an Error by the leaf errors of that Error, recursively.
+// Other errors are kept as they are, nil errors are skipped.
+//
+// CombineErrors returns nil if there are no non-nil errors.
+func CombineErrors(errs ...error) error {
+	var leaves []error
+	for _, err := range errs {
+		leaves = appendLeaves(leaves, err)
+	}
+	if len(leaves) == 0 {
+		return nil
+	}
+	return Error{errors: leaves}
+}
+
+// appendLeaves appends the leaf errors of err to leaves.
+func appendLeaves(leaves []error, err error) []error {
+	if err == nil {
+		return leaves
+	}
+	grouperr, ok := AsGroupError(err)
+	if !ok {
+		return append(leaves, err)
+	}
+	for _, err := range grouperr.errors {
+		leaves = appendLeaves(leaves, err)
+	}
+	return leaves
+}
+
+// values returns the values of the outcomes.
+func values[T any](outcomes []Outcome[T]) []T {
+	vals := make([]T, len(outcomes))
+	for i, outcome := range outcomes {
+		vals[i] = outcome.Value
+	}
+	return vals
+}
+
+// annotate annotates err with msg, preserving Error.
+func annotate(err error, msg string) error {
+	if grouperr, ok := AsGroupError(err); ok {
+		return grouperr.Wrap(msg)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
@@ -0,0 +1,585 @@
+package okgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAll(t *testing.T) {
+	err1, err2 := errors.New("executor_1 failed"), errors.New("executor_2 failed")
+	tests := []struct {
+		name   string
+		fns    []func() (Result, error)
+		want   []Result
+		errors []error
+	}{
+		{
+			name: "only ok responses",
+			fns: []func() (Result, error){
+				func() (Result, error) { return "executor_1", nil },
+				func() (Result, error) { return "executor_2", nil },
+				func() (Result, error) { return "executor_3", nil },
+			},
+			want: []Result{"executor_1", "executor_2", "executor_3"},
+		},
+		{
+			name: "partial results",
+			fns: []func() (Result, error){
+				func() (Result, error) { return "", err1 },
+				func() (Result, error) { return "executor_2", nil },
+				func() (Result, error) { return "executor_3", nil },
+			},
+			want:   []Result{"executor_2", "executor_3"},
+			errors: []error{err1},
+		},
+		{
+			name: "only errors",
+			fns: []func() (Result, error){
+				func() (Result, error) { return "", err1 },
+				func() (Result, error) { return "", err2 },
+			},
+			want:   []Result{},
+			errors: []error{err1, err2},
+		},
+	}
+	for _, tc := range tests {
+		got, err := All(context.Background(), tc.fns...)
+		if (err != nil) != (len(tc.errors) > 0) {
+			t.Fatalf("%s: got err %v, want errors %v", tc.name, err, tc.errors)
+		}
+		for _, wanterr := range tc.errors {
+			if !errors.Is(err, wanterr) {
+				t.Errorf("%s: got err %v, want err %v", tc.name, err, wanterr)
+			}
+		}
+		sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+		if len(got) != len(tc.want) {
+			t.Fatalf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestRace(t *testing.T) {
+	err1, err2 := errors.New("executor_1 failed"), errors.New("executor_2 failed")
+	tests := []struct {
+		name   string
+		fns    []func() (Result, error)
+		want   Result
+		errors []error
+	}{
+		{
+			name: "1 ok response",
+			fns: []func() (Result, error){
+				func() (Result, error) { return "", err1 },
+				func() (Result, error) { return "executor_2", nil },
+			},
+			want: "executor_2",
+		},
+		{
+			name: "only errors",
+			fns: []func() (Result, error){
+				func() (Result, error) { return "", err1 },
+				func() (Result, error) { return "", err2 },
+			},
+			errors: []error{err1, err2},
+		},
+	}
+	for _, tc := range tests {
+		for _, race := range []func(context.Context, ...func() (Result, error)) (Result, error){Race[Result], Any[Result]} {
+			got, err := race(context.Background(), tc.fns...)
+			if (err != nil) != (len(tc.errors) > 0) {
+				t.Fatalf("%s: got err %v, want errors %v", tc.name, err, tc.errors)
+			}
+			for _, wanterr := range tc.errors {
+				if !errors.Is(err, wanterr) {
+					t.Errorf("%s: got err %v, want err %v", tc.name, err, wanterr)
+				}
+			}
+			if got != tc.want {
+				t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestGather(t *testing.T) {
+	err1, err2 := errors.New("executor_1 failed"), errors.New("executor_2 failed")
+	oks, errs := Gather(context.Background(),
+		func() (Result, error) { return "", err1 },
+		func() (Result, error) { return "executor_2", nil },
+		func() (Result, error) { return "", err2 },
+	)
+	if len(oks) != 1 || oks[0] != "executor_2" {
+		t.Errorf("got %v, want [executor_2]", oks)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("got %v, want 2 errors", errs)
+	}
+	for _, wanterr := range []error{err1, err2} {
+		if !errors.Is(errs[0], wanterr) && !errors.Is(errs[1], wanterr) {
+			t.Errorf("got errs %v, want err %v", errs, wanterr)
+		}
+	}
+}
+
+func TestParallel(t *testing.T) {
+	errFailed := errors.New("executor_2 failed")
+	got, err := Parallel(
+		func() (Result, error) { return "executor_1", nil },
+		func() (Result, error) { return "", errFailed },
+		func() (Result, error) { return "executor_3", nil },
+	)
+	if !errors.Is(err, errFailed) {
+		t.Errorf("got err %v, want err %v", err, errFailed)
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+	if len(got) != 2 || got[0] != "executor_1" || got[1] != "executor_3" {
+		t.Errorf("got %v, want [executor_1 executor_3]", got)
+	}
+}
+
+func TestMajority(t *testing.T) {
+	errFailed := errors.New("replica failed")
+	tests := []struct {
+		name    string
+		fns     []func() (Result, error)
+		want    Result
+		wanterr error
+	}{
+		{
+			name: "majority",
+			fns: []func() (Result, error){
+				func() (Result, error) { return "v1", nil },
+				func() (Result, error) { return "v2", nil },
+				func() (Result, error) { return "v1", nil },
+			},
+			want: "v1",
+		},
+		{
+			name: "no majority",
+			fns: []func() (Result, error){
+				func() (Result, error) { return "v1", nil },
+				func() (Result, error) { return "v2", nil },
+				func() (Result, error) { return "v3", nil },
+			},
+			wanterr: ErrNoMajority,
+		},
+		{
+			name: "too many failures",
+			fns: []func() (Result, error){
+				func() (Result, error) { return "v1", nil },
+				func() (Result, error) { return "", errFailed },
+				func() (Result, error) { return "", errFailed },
+				func() (Result, error) { return "v1", nil },
+			},
+			wanterr: ErrNoMajority,
+		},
+	}
+	for _, tc := range tests {
+		got, err := Majority(context.Background(), tc.fns...)
+		if err != tc.wanterr {
+			t.Errorf("%s: got err %v, want err %v", tc.name, err, tc.wanterr)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestMajorityFunc(t *testing.T) {
+	equal := func(a, b []string) bool { return len(a) == len(b) && (len(a) == 0 || a[0] == b[0]) }
+	got, err := MajorityFunc(context.Background(), equal,
+		func() ([]string, error) { return []string{"v1"}, nil },
+		func() ([]string, error) { return []string{"v1"}, nil },
+		func() ([]string, error) { return []string{"v2"}, nil },
+	)
+	if err != nil {
+		t.Fatalf("want nil err, got %v", err)
+	}
+	if len(got) != 1 || got[0] != "v1" {
+		t.Errorf("got %v, want [v1]", got)
+	}
+}
+
+func TestRaceFunc(t *testing.T) {
+	errFailed := errors.New("executor_1 failed")
+	got, err := RaceFunc(
+		func() (Result, error) { return "", errFailed },
+		func() (Result, error) { return "executor_2", nil },
+		func() (Result, error) { time.Sleep(time.Millisecond * 100); return "executor_3", nil },
+	)
+	if err != nil {
+		t.Fatalf("want nil err, got %v", err)
+	}
+	if got != "executor_2" {
+		t.Errorf("got %v, want executor_2", got)
+	}
+	if _, err := RaceFunc(func() (Result, error) { return "", errFailed }); !errors.Is(err, errFailed) {
+		t.Errorf("got err %v, want err %v", err, errFailed)
+	}
+}
+
+func TestLazy(t *testing.T) {
+	var calls int
+	fn := func() (Result, error) { calls++; return "executor", nil }
+	ctx, cancel := context.WithCancel(context.Background())
+	if got, err := Lazy(ctx, fn)(); err != nil || got != "executor" {
+		t.Errorf("got %v, %v, want executor, nil", got, err)
+	}
+	cancel()
+	if _, err := Lazy(ctx, fn)(); !errors.Is(err, context.Canceled) {
+		t.Errorf("got err %v, want err %v", err, context.Canceled)
+	}
+	if calls != 1 {
+		t.Errorf("got %d calls, want 1", calls)
+	}
+}
+
+func TestFirst(t *testing.T) {
+	endpoints := []string{"a.example", "b.example", "c.example"}
+	input, got, err := First(context.Background(), endpoints, func(ctx context.Context, endpoint string) (Result, error) {
+		if endpoint != "b.example" {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "pong", nil
+	})
+	if err != nil {
+		t.Fatalf("want nil err, got %v", err)
+	}
+	if input != "b.example" || got != "pong" {
+		t.Errorf("got %v, %v, want b.example, pong", input, got)
+	}
+
+	errFailed := errors.New("endpoint down")
+	input, _, err = First(context.Background(), endpoints, func(context.Context, string) (Result, error) { return "", errFailed })
+	if !errors.Is(err, errFailed) || input != "" {
+		t.Errorf("got %q, %v, want empty input and err %v", input, err, errFailed)
+	}
+}
+
+func TestJoin2(t *testing.T) {
+	errA, errB := errors.New("metadata failed"), errors.New("content failed")
+	group := func(err error) *Group[Result] {
+		g := New[Result]()
+		g.Go(func() (Result, error) {
+			if err != nil {
+				return "", err
+			}
+			return "ok", nil
+		})
+		return g
+	}
+	tests := []struct {
+		name    string
+		erra    error
+		errb    error
+		wantmsg string
+	}{
+		{name: "both succeed"},
+		{name: "first fails", erra: errA, wantmsg: "first group: metadata failed"},
+		{name: "both fail", erra: errA, errb: errB, wantmsg: "first group: metadata failed;second group: content failed"},
+	}
+	for _, tc := range tests {
+		a, b, err := Join2(group(tc.erra), group(tc.errb))
+		if tc.wantmsg == "" {
+			if err != nil || a != "ok" || b != "ok" {
+				t.Errorf("%s: got %v, %v, %v, want ok, ok, nil", tc.name, a, b, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tc.wantmsg {
+			t.Errorf("%s: got err %v, want %q", tc.name, err, tc.wantmsg)
+		}
+		if errors.Is(err, errA) != (tc.erra != nil) || errors.Is(err, errB) != (tc.errb != nil) {
+			t.Errorf("%s: got err %v, want it to match the failed groups", tc.name, err)
+		}
+	}
+}
+
+func TestCombineErrors(t *testing.T) {
+	err1, err2, err3 := errors.New("err1"), errors.New("err2"), errors.New("err3")
+	inner := Error{errors: []error{err1, GoroutineError{Err: Error{errors: []error{err2}}}}}
+	tests := []struct {
+		errs []error
+		want []error
+	}{
+		{nil, nil},
+		{[]error{nil, nil}, nil},
+		{[]error{err1, nil, err2}, []error{err1, err2}},
+		{[]error{inner, err3}, []error{err1, err2, err3}},
+		{[]error{fmt.Errorf("wrapped: %w", inner)}, []error{err1, err2}},
+	}
+	for _, tc := range tests {
+		err := CombineErrors(tc.errs...)
+		if tc.want == nil {
+			if err != nil {
+				t.Errorf("got err %v, want nil", err)
+			}
+			continue
+		}
+		grouperr, ok := AsGroupError(err)
+		if !ok {
+			t.Errorf("got err %v, want Error", err)
+			continue
+		}
+		if got := grouperr.Slice(); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("got %v, want %v", got, tc.want)
+		}
+	}
+}
+
+func TestAnyTrue(t *testing.T) {
+	errFailed := errors.New("pred failed")
+	tests := []struct {
+		inputs  []int
+		want    bool
+		wantErr error
+	}{
+		{inputs: nil, want: false},
+		{inputs: []int{1, 3, 5}, want: false},
+		{inputs: []int{1, 2, -1}, want: true},
+		{inputs: []int{1, -1}, want: false, wantErr: errFailed},
+	}
+	for _, tc := range tests {
+		var canceled int32
+		got, err := AnyTrue(context.Background(), tc.inputs, func(ctx context.Context, i int) (bool, error) {
+			switch {
+			case i < 0:
+				select {
+				case <-ctx.Done():
+					atomic.AddInt32(&canceled, 1)
+					return false, ctx.Err()
+				case <-time.After(50 * time.Millisecond):
+					return false, errFailed
+				}
+			case i%2 == 0:
+				return true, nil
+			}
+			return false, nil
+		})
+		if got != tc.want || !errors.Is(err, tc.wantErr) {
+			t.Errorf("%v: got %t, %v, want %t, %v", tc.inputs, got, err, tc.want, tc.wantErr)
+		}
+		if tc.want && atomic.LoadInt32(&canceled) != 1 {
+			t.Errorf("%v: want slow predicate canceled by true", tc.inputs)
+		}
+	}
+}
+
+func TestAllTrue(t *testing.T) {
+	errFailed := errors.New("pred failed")
+	tests := []struct {
+		inputs  []int
+		want    bool
+		wantErr error
+	}{
+		{inputs: nil, want: true},
+		{inputs: []int{2, 4, 6}, want: true},
+		{inputs: []int{2, 1, -2}, want: false},
+		{inputs: []int{2, 0, -2}, want: false, wantErr: errFailed},
+	}
+	for _, tc := range tests {
+		var canceled int32
+		got, err := AllTrue(context.Background(), tc.inputs, func(ctx context.Context, i int) (bool, error) {
+			switch {
+			case i < 0:
+				select {
+				case <-ctx.Done():
+					atomic.AddInt32(&canceled, 1)
+					return false, ctx.Err()
+				case <-time.After(time.Second):
+					return true, nil
+				}
+			case i == 0:
+				return false, errFailed
+			}
+			return i%2 == 0, nil
+		})
+		if got != tc.want || !errors.Is(err, tc.wantErr) {
+			t.Errorf("%v: got %t, %v, want %t, %v", tc.inputs, got, err, tc.want, tc.wantErr)
+		}
+		if !tc.want && atomic.LoadInt32(&canceled) != 1 {
+			t.Errorf("%v: want slow predicate canceled by the first false", tc.inputs)
+		}
+	}
+}
+
+func TestWaitUnique(t *testing.T) {
+	g := New[int](WithNoCancelOnSuccess())
+	for i, v := range []int{1, 2, 1, 3, 2, 1} {
+		i, v := i, v
+		g.Go(func() (int, error) {
+			time.Sleep(time.Duration(i) * 5 * time.Millisecond)
+			return v, nil
+		})
+	}
+	g.Go(func() (int, error) { return 0, errors.New("executor failed") })
+	got, err := WaitUnique(g)
+	if err == nil {
+		t.Error("want err, got nil")
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestForEach(t *testing.T) {
+	errStop := errors.New("stop")
+	tests := []struct {
+		opts   []Option
+		stopAt int
+		want   []int
+		err    error
+	}{
+		{opts: []Option{WithNoCancelOnSuccess()}, want: []int{1, 2, 3, 4}},
+		{opts: []Option{WithNoCancelOnSuccess(), WithResults()}, want: []int{1, 2, 3, 4}},
+		{opts: []Option{WithNoCancelOnSuccess()}, stopAt: 2, want: []int{1, 2}, err: errStop},
+		{opts: []Option{WithNoCancelOnSuccess(), WithResults()}, stopAt: 2, want: []int{1, 2}, err: errStop},
+	}
+	for _, tc := range tests {
+		g, ctx := WithContext[int](context.Background(), tc.opts...)
+		for i := 1; i <= 4; i++ {
+			i := i
+			g.Go(func() (int, error) {
+				select {
+				case <-time.After(time.Duration(i) * 5 * time.Millisecond):
+					return i, nil
+				case <-ctx.Done():
+					return 0, ctx.Err()
+				}
+			})
+		}
+		var got []int
+		err := ForEach(g, func(v int) error {
+			got = append(got, v)
+			if len(got) == tc.stopAt {
+				return errStop
+			}
+			return nil
+		})
+		if err != tc.err {
+			t.Errorf("got %v, want %v", err, tc.err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("got %v, want %v", got, tc.want)
+		}
+		if tc.err != nil && context.Cause(ctx) != tc.err {
+			t.Errorf("got %v cause, want %v", context.Cause(ctx), tc.err)
+		}
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	errFailed := errors.New("executor failed")
+	tests := []struct {
+		first error
+		stage error
+		want  string
+		err   error
+	}{
+		{want: "2:executor"},
+		{first: errFailed, err: errFailed},
+		{stage: errFailed, err: errFailed},
+	}
+	for _, tc := range tests {
+		g, _ := WithContext[string](context.Background())
+		g.Go(func() (string, error) { return "executor", tc.first })
+		p := Pipeline(g, func(ctx context.Context, v string) (*Group[string], error) {
+			if tc.stage != nil {
+				return nil, tc.stage
+			}
+			next, _ := WithContext[string](ctx)
+			for i := 1; i <= 2; i++ {
+				i := i
+				next.GoCtx(func(ctx context.Context) (string, error) {
+					if i == 1 {
+						return "", errFailed
+					}
+					return fmt.Sprintf("%d:%s", i, v), nil
+				})
+			}
+			return next, nil
+		})
+		got, err := p.Wait()
+		if got != tc.want {
+			t.Errorf("got %v, want %v", got, tc.want)
+		}
+		if !errors.Is(err, tc.err) {
+			t.Errorf("got %v, want %v", err, tc.err)
+		}
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	errFailed := errors.New("executor failed")
+	tests := []struct {
+		results [][]error
+		want    bool
+	}{
+		{results: [][]error{{errFailed, errFailed}, {errFailed, nil}}, want: true},
+		{results: [][]error{{errFailed}, {errFailed, errFailed}}},
+		{results: [][]error{{nil}, {nil}}, want: true},
+	}
+	for _, tc := range tests {
+		var groups []*Group[string]
+		for i, errs := range tc.results {
+			g := New[string]()
+			for j, err := range errs {
+				ok, err := fmt.Sprintf("group_%d_executor_%d", i, j), err
+				g.Go(func() (string, error) { return ok, err })
+			}
+			groups = append(groups, g)
+		}
+		got, err := Coalesce(groups...).Wait()
+		if (err == nil) != tc.want || (got != "") != tc.want {
+			t.Errorf("got %v, %v, want ok response %t", got, err, tc.want)
+		}
+		if !tc.want {
+			if grouperr, ok := AsGroupError(err); !ok || len(grouperr.Errors()) != len(groups) {
+				t.Errorf("got %v, want %d errors", err, len(groups))
+			}
+		}
+		for _, g := range groups {
+			if g.Snapshot().Running != 0 {
+				t.Error("want all groups waited")
+			}
+		}
+	}
+}
+
+func TestPipeline_Timeout(t *testing.T) {
+	g, _ := WithTimeout[string](context.Background(), 50*time.Millisecond)
+	g.Go(func() (string, error) { return "executor", nil })
+	start := time.Now()
+	p := Pipeline(g, func(ctx context.Context, v string) (*Group[string], error) {
+		next, ctx := WithContext[string](ctx)
+		next.Go(func() (string, error) {
+			select {
+			case <-time.After(time.Second):
+				return v, nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		})
+		return next, nil
+	})
+	_, err := p.Wait()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("got the stage finishing after %v, want it capped by the group's timeout", elapsed)
+	}
+}
@@ -0,0 +1,112 @@
+package okgroup
+
+import (
+	"sync"
+	"time"
+)
+
+// Metrics receives metrics of a Group configured with WithMetrics.
+//
+// It allows to export the metrics to a monitoring system, such as
+// Prometheus counters and histograms. Methods are called concurrently
+// from goroutines of the group and must not block.
+type Metrics interface {
+	// IncSubmitted is called when a function is passed to the group.
+	IncSubmitted()
+	// IncCompleted is called when a function returns.
+	IncCompleted()
+	// IncErrored is called when a function fails.
+	IncErrored()
+	// ObserveDuration is called with the duration of each function call.
+	ObserveDuration(d time.Duration)
+	// SetWinner is called when the group has an ok response.
+	SetWinner()
+}
+
+// NopMetrics is a Metrics discarding all metrics, it is the default of a Group.
+type NopMetrics struct{}
+
+func (NopMetrics) IncSubmitted()                 {}
+func (NopMetrics) IncCompleted()                 {}
+func (NopMetrics) IncErrored()                   {}
+func (NopMetrics) ObserveDuration(time.Duration) {}
+func (NopMetrics) SetWinner()                    {}
+
+// MemoryMetrics is a Metrics keeping metrics in memory.
+//
+// It is intended for tests. The zero value is ready to use.
+type MemoryMetrics struct {
+	mu        sync.Mutex
+	submitted int
+	completed int
+	errored   int
+	winners   int
+	durations []time.Duration
+}
+
+func (m *MemoryMetrics) IncSubmitted() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.submitted++
+}
+
+func (m *MemoryMetrics) IncCompleted() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.completed++
+}
+
+func (m *MemoryMetrics) IncErrored() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errored++
+}
+
+func (m *MemoryMetrics) ObserveDuration(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.durations = append(m.durations, d)
+}
+
+func (m *MemoryMetrics) SetWinner() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.winners++
+}
+
+// Submitted returns the number of IncSubmitted calls.
+func (m *MemoryMetrics) Submitted() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.submitted
+}
+
+// Completed returns the number of IncCompleted calls.
+func (m *MemoryMetrics) Completed() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.completed
+}
+
+// Errored returns the number of IncErrored calls.
+func (m *MemoryMetrics) Errored() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.errored
+}
+
+// Winners returns the number of SetWinner calls.
+func (m *MemoryMetrics) Winners() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.winners
+}
+
+// Durations returns the observed durations in the order they were observed.
+func (m *MemoryMetrics) Durations() []time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	durations := make([]time.Duration, len(m.durations))
+	copy(durations, m.durations)
+	return durations
+}
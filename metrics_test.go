@@ -0,0 +1,34 @@
+package okgroup
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithMetrics(t *testing.T) {
+	m := &MemoryMetrics{}
+	g := New[Result](WithMetrics(m), WithNoCancelOnSuccess())
+	g.Go(func() (Result, error) { return "executor_1", nil })
+	g.Go(func() (Result, error) { return "", errors.New("executor_2 failed") })
+	g.GoBatch([]func() (Result, error){
+		func() (Result, error) { return "executor_3", nil },
+		func() (Result, error) { return "", errors.New("executor_4 failed") },
+	})
+	g.Wait()
+	tests := []struct {
+		metric string
+		got    int
+		want   int
+	}{
+		{"submitted", m.Submitted(), 4},
+		{"completed", m.Completed(), 4},
+		{"errored", m.Errored(), 2},
+		{"winners", m.Winners(), 1},
+		{"durations", len(m.Durations()), 4},
+	}
+	for _, tc := range tests {
+		if tc.got != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.metric, tc.got, tc.want)
+		}
+	}
+}
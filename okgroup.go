@@ -4,24 +4,131 @@ package okgroup
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrNotOK is the error of a function whose value was rejected
+	// by the predicate configured with WithOK.
+	ErrNotOK = errors.New("okgroup: not an ok response")
+	// ErrNoQuorum is part of the group's error if the group had ok responses,
+	// but fewer than the quorum configured with WithQuorum.
+	ErrNoQuorum = errors.New("okgroup: quorum not reached")
+	// ErrCircuitOpen is the error of a function which was not executed
+	// because the circuit breaker configured with WithCircuitBreaker was open.
+	ErrCircuitOpen = errors.New("okgroup: circuit breaker is open")
+	// ErrNoGoroutines is the error returned by Wait if no function was passed to the group.
+	ErrNoGoroutines = errors.New("okgroup: no goroutines")
+	// ErrGroupCanceled matches an Error whose errors are all context.Canceled,
+	// which means the group's context was canceled before any ok response.
+	ErrGroupCanceled = errors.New("okgroup: group canceled")
+	// ErrGroupTimeout is the error returned by WaitWithTimeout
+	// if the timeout expires before the group completes. It is also the cause
+	// of the group's context cancellation because of the timeout, see context.Cause.
+	ErrGroupTimeout = errors.New("okgroup: wait timed out")
+	// ErrGroupFull is the error returned by TryGo if the group runs
+	// the maximum number of goroutines or has run as many as the cap
+	// configured with WithMaxGoroutines.
+	ErrGroupFull = errors.New("okgroup: group is full")
+	// ErrGroupFrozen is the error returned by TryGo if the group is frozen.
+	ErrGroupFrozen = errors.New("okgroup: group is frozen")
+	// ErrWon is the cause of the group's context cancellation
+	// if a function returned an ok response, see context.Cause.
+	ErrWon = errors.New("okgroup: group won")
 )
 
 // An Error is a group's error containing errors from all goroutines if a group fails.
 type Error struct {
+	msg    string
 	errors []error
+	cause  error
+	// partial reports whether some functions returned ok responses.
+	partial bool
 }
 
 func (e Error) Error() string {
-	var msg string
-	for _, err := range e.errors {
-		msg += err.Error() + ";"
+	msgs := make([]string, len(e.errors))
+	for i, err := range e.errors {
+		msgs[i] = err.Error()
+	}
+	msg := strings.Join(msgs, ";")
+	if e.msg == "" {
+		return msg
+	}
+	if msg == "" {
+		return e.msg
+	}
+	return e.msg + ": " + msg
+}
+
+// Format implements fmt.Formatter.
+//
+// The %v and %s verbs print the result of the Error method, %q quotes it.
+// The %+v verb prints each error on its own indented line along with its position.
+// The %d verb prints the number of errors.
+func (e Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			if e.msg != "" {
+				io.WriteString(s, e.msg+":")
+			} else {
+				io.WriteString(s, "okgroup:")
+			}
+			for i, err := range e.errors {
+				msg := strings.ReplaceAll(fmt.Sprintf("%+v", err), "\n", "\n    ")
+				fmt.Fprintf(s, "\n    [%d] %s", i, msg)
+			}
+			return
+		}
+		io.WriteString(s, e.Error())
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	case 'd':
+		fmt.Fprintf(s, "%d", len(e.errors))
+	default:
+		fmt.Fprintf(s, "%%!%c(okgroup.Error=%s)", verb, e.Error())
+	}
+}
+
+// Wrap returns a copy of the Error annotated with msg.
+//
+// Unlike fmt.Errorf, the returned error is still an Error,
+// the msg is prepended to the result of its Error method.
+func (e Error) Wrap(msg string) error {
+	if e.msg != "" {
+		msg += ": " + e.msg
 	}
-	return msg[:len(msg)-1]
+	e.msg = msg
+	return e
+}
+
+// Cause returns the error which canceled the group's context if the group
+// was configured with WithCancelOnFirstFailure, nil otherwise.
+func (e Error) Cause() error {
+	return e.cause
 }
 
 func (e Error) Is(target error) bool {
+	if target == ErrGroupCanceled {
+		return e.canceled()
+	}
 	for _, err := range e.errors {
 		if errors.Is(err, target) {
 			return true
@@ -30,69 +137,1933 @@ func (e Error) Is(target error) bool {
 	return false
 }
 
+// Timeout reports whether any of the errors is a timeout, that is
+// context.DeadlineExceeded or an error with a Timeout method reporting true,
+// following the net.Error convention.
+func (e Error) Timeout() bool {
+	for _, err := range e.errors {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return true
+		}
+		var timeout interface{ Timeout() bool }
+		if errors.As(err, &timeout) && timeout.Timeout() {
+			return true
+		}
+	}
+	return false
+}
+
+// Temporary reports whether all the errors are temporary, that is
+// context.Canceled, context.DeadlineExceeded or an error with a Temporary method
+// reporting true, following the net.Error convention.
+// It reports false for an Error without errors.
+func (e Error) Temporary() bool {
+	for _, err := range e.errors {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			continue
+		}
+		var temporary interface{ Temporary() bool }
+		if !errors.As(err, &temporary) || !temporary.Temporary() {
+			return false
+		}
+	}
+	return len(e.errors) > 0
+}
+
+// canceled reports whether the Error contains only context.Canceled errors.
+func (e Error) canceled() bool {
+	for _, err := range e.errors {
+		if !errors.Is(err, context.Canceled) {
+			return false
+		}
+	}
+	return len(e.errors) > 0
+}
+
+// Key returns a key identifying the set of errors the Error contains.
+//
+// The key is derived from the sorted messages of the errors, so it is stable
+// for groups failing with the same errors regardless of their order.
+// It allows to use an Error as a map key.
+func (e Error) Key() string {
+	msgs := make([]string, len(e.errors))
+	for i, err := range e.errors {
+		msgs[i] = err.Error()
+	}
+	sort.Strings(msgs)
+	h := sha256.New()
+	for _, msg := range msgs {
+		h.Write([]byte(msg))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (e Error) As(target any) bool {
+	for _, err := range e.errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the errors the Error contains, so it composes
+// with multi-error unwrapping of errors.Is and errors.As. Any error
+// returned by a function can be extracted by calling errors.As with the group's error.
+func (e Error) Unwrap() []error {
+	return e.Errors()
+}
+
+// Errors returns a copy of the errors the Error contains,
+// in the order they were returned by functions.
+func (e Error) Errors() []error {
+	errs := make([]error, len(e.errors))
+	copy(errs, e.errors)
+	return errs
+}
+
+// Slice is like Errors, it returns a copy of the errors the Error contains
+// as a flat slice, which allows to pass them to errors.Join.
+func (e Error) Slice() []error {
+	return e.Errors()
+}
+
+// BySource returns errors keyed by the name of the goroutine which returned them.
+//
+// Errors returned from unnamed goroutines are keyed by the goroutine's index.
+// If multiple goroutines share a name, the last error is kept.
+func (e Error) BySource() map[string]error {
+	m := make(map[string]error, len(e.errors))
+	for _, err := range e.errors {
+		var goerr GoroutineError
+		if !errors.As(err, &goerr) {
+			continue
+		}
+		key := goerr.Name
+		if key == "" {
+			key = strconv.Itoa(goerr.Index)
+		}
+		m[key] = goerr.Err
+	}
+	return m
+}
+
+// IsGroupError reports whether any error in err's chain is an Error.
+func IsGroupError(err error) bool {
+	_, ok := AsGroupError(err)
+	return ok
+}
+
+// AsGroupError finds the first error in err's chain that is an Error.
+func AsGroupError(err error) (Error, bool) {
+	var grouperr Error
+	ok := errors.As(err, &grouperr)
+	return grouperr, ok
+}
+
+// IsPartialFailure reports whether err's chain has an Error of a group
+// in which some functions failed and some returned ok responses, such as
+// the one returned by WaitAll or by Wait if the quorum was not reached.
+func IsPartialFailure(err error) bool {
+	grouperr, ok := AsGroupError(err)
+	return ok && grouperr.partial
+}
+
+// IsTotalFailure reports whether err's chain has an Error of a group
+// in which no function returned an ok response.
+func IsTotalFailure(err error) bool {
+	grouperr, ok := AsGroupError(err)
+	return ok && !grouperr.partial
+}
+
+// A GoroutineError is an error returned by a function passed to a Group
+// attributed to the goroutine which executed the function.
+type GoroutineError struct {
+	// Index is the 0-based order in which the function was passed to the group.
+	Index int
+	// Name is the goroutine's name passed to GoNamed or the key passed to GoKeyed,
+	// empty otherwise.
+	Name string
+	// Meta is the metadata passed to GoWithMeta, nil otherwise.
+	Meta any
+	// Err is the error returned by the function.
+	Err error
+}
+
+func (e GoroutineError) Error() string {
+	return e.Err.Error()
+}
+
+func (e GoroutineError) Unwrap() error {
+	return e.Err
+}
+
+// A PanicError is the error of a function which panicked.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+func (e PanicError) Error() string {
+	return fmt.Sprintf("okgroup: panic: %v", e.Value)
+}
+
+// task identifies a function passed to the group.
+type task struct {
+	index int
+	name  string
+	meta  any
+	// ctx is the function's own context derived from the group's context, if any.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// release releases the function's own context, if any.
+func (t task) release() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+}
+
+// A Handle refers to a function passed to a Group by calling GoCtx.
+type Handle struct {
+	cancel context.CancelFunc
+}
+
+// Cancel cancels the context of the function, without canceling the group's context
+// or other functions. The function's error is then part of the group's error
+// as any other error.
+func (h Handle) Cancel() {
+	h.cancel()
+}
+
+// okResponse is an ok response along with the index and the metadata
+// of the function returning it.
+type okResponse[T any] struct {
+	index    int
+	meta     any
+	value    T
+	duration time.Duration
+}
+
+// A MetaResult is an ok response along with the metadata
+// attached to the function by GoWithMeta.
+type MetaResult[T any] struct {
+	Value T
+	Meta  any
+}
+
+// An Outcome is the result of a Group or of a single function passed to it.
+type Outcome[T any] struct {
+	Value T
+	Err   error
+	// Index is the index of the function producing the outcome,
+	// it is zero for the outcome of a group.
+	Index int
+	// Duration is how long the function producing the outcome took,
+	// it is zero for the outcome of a group.
+	Duration time.Duration
+}
+
+// A Cache stores ok responses of functions passed to GoKeyed.
+//
+// A Cache must be safe for concurrent use.
+type Cache[T any] interface {
+	Get(key string) (T, bool)
+	Set(key string, val T)
+}
+
+// A GoroutinePool executes functions passed to a Group
+// instead of starting a new goroutine for each of them.
+//
+// Submit returns an error if the function cannot be executed,
+// the function is then treated as if it failed with that error.
+type GoroutinePool interface {
+	Submit(task func()) error
+}
+
 // A Group is a collection of goroutines executing functions
 // having the same signature func() (T, error) where T is any type.
 type Group[T any] struct {
-	cancel func()
-	wg     sync.WaitGroup
-	errCh  chan error
-	okCh   chan T
+	name   string
+	opts   []Option
+	newCtx func() (context.Context, context.CancelCauseFunc)
+	// parent is the Context the group's context is derived from,
+	// nil for a group created by calling New or WithStop.
+	parent context.Context
+	// stopCh is the channel passed to WithStop, nil for other groups.
+	stopCh    <-chan struct{}
+	ctx       context.Context
+	cancel    context.CancelCauseFunc
+	wg        sync.WaitGroup
+	submitted int32
+	frozen    int32
+	// admitted counts functions passed to a group configured with WithMaxGoroutines,
+	// it may exceed maxGoroutines by the number of rejected ones.
+	admitted      int32
+	maxGoroutines int32
+	discarded     int32
+	// submitDeadline is the Unix time in nanoseconds set by Deadline, 0 if none.
+	submitDeadline atomic.Int64
+
+	mu   sync.Mutex
+	oks  []okResponse[T]
+	errs []error
+	// dropped are errors discarded because of an ok response, see Discarded.
+	dropped []error
+	failed  int
+	winner  atomic.Pointer[okResponse[T]]
+	winCh   chan struct{}
+
+	keepPartial bool
+	partials    []T
+	onWin       func(T)
+	logger      *slog.Logger
+	score       func(T) float64
+	fallback    *T
+
+	cancelOnFailure   bool
+	noCancelOnSuccess bool
+	discardLateErrors bool
+	indexedErrors     bool
+	errBuffer         int
+	cause             error
+	firstErr          atomic.Pointer[error]
+
+	sem    chan struct{}
+	quorum int
+	isOK   func(T) bool
+	cache  Cache[T]
+	pool   GoroutinePool
+
+	spanner func(ctx context.Context, index int, name string) (context.Context, func())
+	// propagate are keys of values copied from the group's context, see WithContextPropagation.
+	propagate []any
+
+	breakerThreshold int
+	breakerReset     time.Duration
+	failures         int
+	lastFailure      time.Time
+
+	progress chan<- float64
+
+	stopCancelTimer func() bool
+	clock           clock
+	metrics         Metrics
+	created         time.Time
+	completed       int32
+	cancelAt        int32
+	onCancel        func()
+	onPanic         func(index int, recovered any) error
+	taps            []func(T, error)
+	stopOnce        sync.Once
+
+	waitOnce sync.Once
+	waiting  int32
+	lostCh   chan struct{}
+	lostOnce sync.Once
+	waited   chan struct{}
+	doneCh   chan struct{}
+	// settledCh is closed once all functions of a frozen group have returned.
+	settledCh  chan struct{}
+	settleOnce sync.Once
+	// scoreOnce guards declaring the winner of a group configured with SetScore.
+	scoreOnce  sync.Once
+	results    chan Outcome[T]
+	waitChOnce sync.Once
+	waitCh     chan Outcome[T]
+
+	streamOnce   sync.Once
+	stream       chan T
+	streamNotify chan struct{}
+	streamErr    error
+
+	// outcomes are outcomes of functions tracked for Seq2 once
+	// outcomesCond is set, both are guarded by mu.
+	outcomes     []Outcome[T]
+	outcomesCond *sync.Cond
+}
+
+// New returns a new Group.
+//
+// A Group created by calling New has no context, its goroutines are never canceled.
+func New[T any](opts ...Option) *Group[T] {
+	return newGroup[T](func() (context.Context, context.CancelCauseFunc) {
+		return context.Background(), nil
+	}, opts)
 }
 
 // WithContext returns a new Group and a derived Context from a given ctx.
 //
 // The derived Context is canceled if a function passed to Go returns
 // an ok response or the first time Wait returns.
-func WithContext[T any](ctx context.Context) (*Group[T], context.Context) {
-	ctx, cancel := context.WithCancel(ctx)
-	return &Group[T]{cancel: cancel, errCh: make(chan error), okCh: make(chan T, 1)}, ctx
+// context.Cause reports ErrWon if the Context was canceled because of an ok response,
+// the failure if it was canceled because of WithCancelOnFirstFailure
+// and the parent's cause if the parent was canceled.
+func WithContext[T any](ctx context.Context, opts ...Option) (*Group[T], context.Context) {
+	g := newGroup[T](func() (context.Context, context.CancelCauseFunc) {
+		return context.WithCancelCause(ctx)
+	}, opts)
+	g.parent = ctx
+	return g, g.ctx
+}
+
+// WithTimeout is like WithContext but the derived Context is also canceled
+// once the timeout d elapses, which caps the lifetime of the group.
+//
+// Wait then returns the ok response, if there is one, otherwise the group's error
+// which contains context.DeadlineExceeded if some functions have not returned in time.
+func WithTimeout[T any](parent context.Context, d time.Duration, opts ...Option) (*Group[T], context.Context) {
+	g := newGroup[T](func() (context.Context, context.CancelCauseFunc) {
+		ctx, cancelTimeout := context.WithTimeoutCause(parent, d, ErrGroupTimeout)
+		ctx, cancelCause := context.WithCancelCause(ctx)
+		return ctx, func(cause error) {
+			cancelCause(cause)
+			cancelTimeout()
+		}
+	}, opts)
+	g.parent = parent
+	return g, g.ctx
+}
+
+// WithStop is like WithContext but the derived Context is canceled
+// once the stop channel is closed, as if the parent Context was canceled.
+//
+// It is intended for codebases signalling cancellation by closing a channel.
+// The channel is watched by a goroutine which exits once the channel is closed
+// or the group's context is done, e.g. after Wait, so a channel closed only
+// at shutdown does not keep it running. Groups derived from the group,
+// such as its clones, watch the channel in their own goroutines.
+func WithStop[T any](stop <-chan struct{}, opts ...Option) (*Group[T], context.Context) {
+	g := newGroup[T](func() (context.Context, context.CancelCauseFunc) {
+		return watchStop(context.Background(), stop)
+	}, opts)
+	g.stopCh = stop
+	return g, g.ctx
+}
+
+// watchStop returns a Context derived from parent which is canceled once
+// the stop channel is closed. The watching goroutine exits once the returned
+// Context is done. A nil stop channel is not watched.
+func watchStop(parent context.Context, stop <-chan struct{}) (context.Context, context.CancelCauseFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	if stop == nil {
+		return ctx, cancel
+	}
+	go func() {
+		select {
+		case <-stop:
+			cancel(nil)
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// derive returns a function deriving a context which shares the group's lifetime:
+// it is canceled along with the group's parent Context, its stop channel and its deadline.
+func (g *Group[T]) derive() func() (context.Context, context.CancelCauseFunc) {
+	parent, stop := g.parent, g.stopCh
+	if parent == nil {
+		if stop == nil {
+			return func() (context.Context, context.CancelCauseFunc) {
+				return context.Background(), nil
+			}
+		}
+		parent = context.Background()
+	}
+	deadline, ok := g.ctx.Deadline()
+	return func() (context.Context, context.CancelCauseFunc) {
+		if !ok {
+			return watchStop(parent, stop)
+		}
+		ctx, cancelDeadline := context.WithDeadlineCause(parent, deadline, ErrGroupTimeout)
+		ctx, cancelCause := watchStop(ctx, stop)
+		return ctx, func(cause error) {
+			cancelCause(cause)
+			cancelDeadline()
+		}
+	}
+}
+
+// newGroup returns a new Group whose context is derived by calling newCtx.
+func newGroup[T any](newCtx func() (context.Context, context.CancelCauseFunc), opts []Option) *Group[T] {
+	g := &Group[T]{newCtx: newCtx, opts: opts, clock: clk, winCh: make(chan struct{}), lostCh: make(chan struct{}), doneCh: make(chan struct{}), settledCh: make(chan struct{}), quorum: 1}
+	g.ctx, g.cancel = newCtx()
+	g.created = g.clock.Now()
+	g.apply(opts)
+	return g
+}
+
+// Clone returns a new Group with the same configuration as the template,
+// including the one set by setters such as OnWin, but without any functions.
+//
+// The clone's context is derived the same way as the template's one, so
+// the clone of a group created by calling WithContext is canceled along
+// with the same parent Context. Use Context to obtain it.
+// Clone panics if the template has functions which have not returned yet.
+func Clone[T any](template *Group[T]) *Group[T] {
+	if template.Snapshot().Running > 0 {
+		panic("okgroup: Clone called with running goroutines")
+	}
+	g := newGroup[T](template.newCtx, template.opts)
+	g.parent = template.parent
+	g.stopCh = template.stopCh
+	g.keepPartial = template.keepPartial
+	g.onWin = template.onWin
+	g.logger = template.logger
+	g.score = template.score
+	g.onCancel = template.onCancel
+	g.onPanic = template.onPanic
+	g.spanner = template.spanner
+	g.taps = append([]func(T, error){}, template.taps...)
+	return g
+}
+
+// Context returns the group's context.
+func (g *Group[T]) Context() context.Context {
+	return g.ctx
+}
+
+// SetKeepPartial is the setter equivalent of WithKeepPartial, which is the preferred
+// way to configure the group. It panics if called after Go.
+func (g *Group[T]) SetKeepPartial(keep bool) {
+	g.checkNotStarted("SetKeepPartial")
+	g.keepPartial = keep
+}
+
+// SetScore is the setter equivalent of WithScore, which is the preferred
+// way to configure the group. It panics if called after Go.
+func (g *Group[T]) SetScore(score func(T) float64) {
+	g.checkNotStarted("SetScore")
+	g.score = score
+}
+
+// SetLogger is the setter equivalent of WithLogger, which is the preferred
+// way to configure the group. It panics if called after Go.
+func (g *Group[T]) SetLogger(logger *slog.Logger) {
+	g.checkNotStarted("SetLogger")
+	g.logger = logger
+}
+
+// log logs msg with attrs at the debug level. The group's logger must be set.
+func (g *Group[T]) log(msg string, attrs ...slog.Attr) {
+	if g.name != "" {
+		attrs = append(attrs, slog.String("group", g.name))
+	}
+	g.logger.LogAttrs(context.Background(), slog.LevelDebug, msg, attrs...)
+}
+
+// OnWin is the setter equivalent of WithOnWin, which is the preferred
+// way to configure the group. It panics if called after Go.
+func (g *Group[T]) OnWin(f func(T)) {
+	g.checkNotStarted("OnWin")
+	g.onWin = f
+}
+
+// OnCancel is the setter equivalent of WithOnCancel, which is the preferred
+// way to configure the group. It panics if called after Go.
+func (g *Group[T]) OnCancel(f func()) {
+	g.checkNotStarted("OnCancel")
+	g.onCancel = f
+}
+
+// OnPanic is the setter equivalent of WithOnPanic, which is the preferred
+// way to configure the group. It panics if called after Go.
+func (g *Group[T]) OnPanic(f func(index int, recovered any) error) {
+	g.checkNotStarted("OnPanic")
+	g.onPanic = f
+}
+
+// Tap is the setter equivalent of WithTap, which is the preferred
+// way to configure the group. It panics if called after Go.
+func (g *Group[T]) Tap(fn func(T, error)) {
+	g.checkNotStarted("Tap")
+	g.taps = append(g.taps, fn)
+}
+
+// SetSpanner is the setter equivalent of WithSpanner, which is the preferred
+// way to configure the group. A nil spanner restores the default, which passes
+// the group's context. It panics if called after Go.
+func (g *Group[T]) SetSpanner(spanner func(ctx context.Context, index int, name string) (context.Context, func())) {
+	g.checkNotStarted("SetSpanner")
+	g.spanner = spanner
 }
 
 // Go executes a given function in a new goroutine.
 //
 // The first function returning an ok response cancel the group's context,
 // if the group was created by calling WithContext.
-// The ok response is returned by Wait.
+// The ok response is returned by Wait. Errors of functions canceled
+// because of the ok response are not part of the group's error.
 func (g *Group[T]) Go(f func() (T, error)) {
+	g.start(task{}, ignoreCtx(f))
+}
+
+// GoNamed is like Go but names the goroutine executing the function.
+//
+// An error returned by the function is attributed to the name by GoroutineError.
+func (g *Group[T]) GoNamed(name string, f func() (T, error)) {
+	g.start(task{name: name}, ignoreCtx(f))
+}
+
+// GoCtx is like Go but passes the function the goroutine's context.
+//
+// The context is derived from the group's context, it is also canceled
+// by calling Cancel on the returned Handle. If the group was configured
+// with WithSpanner, the context is passed through the spanner.
+func (g *Group[T]) GoCtx(f func(ctx context.Context) (T, error)) Handle {
+	ctx, cancel := context.WithCancel(g.ctx)
+	g.start(task{ctx: ctx, cancel: cancel}, f)
+	return Handle{cancel: cancel}
+}
+
+// TryGo is like Go but does not block if the limit configured with WithLimit
+// or the cap configured with WithMaxGoroutines is reached, it returns ErrGroupFull instead
+// and the function is not executed. If the group is frozen, TryGo returns ErrGroupFrozen.
+func (g *Group[T]) TryGo(f func() (T, error)) error {
+	if g.IsFrozen() {
+		return ErrGroupFrozen
+	}
+	if g.pastDeadline() {
+		return nil
+	}
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return ErrGroupFull
+		}
+	}
+	if !g.admit() {
+		if g.sem != nil {
+			<-g.sem
+		}
+		return ErrGroupFull
+	}
+	g.launch(task{}, ignoreCtx(f))
+	return nil
+}
+
+// GoBatch is like calling Go for each of the functions, but adds them
+// to the group at once, which is cheaper for large batches.
+func (g *Group[T]) GoBatch(fns []func() (T, error)) {
+	g.checkNotFrozen()
+	if g.pastDeadline() {
+		return
+	}
+	if g.sem != nil || g.breakerThreshold > 0 || g.pool != nil || g.maxGoroutines > 0 {
+		// Each function has to acquire the semaphore, pass the circuit breaker,
+		// be submitted to the pool or fit under the cap.
+		for _, f := range fns {
+			g.start(task{}, ignoreCtx(f))
+		}
+		return
+	}
+	n := len(fns)
+	first := int(atomic.AddInt32(&g.submitted, int32(n))) - n
+	g.wg.Add(n)
+	for i, f := range fns {
+		g.metrics.IncSubmitted()
+		if g.logger != nil {
+			g.log("okgroup: function submitted", slog.Int("index", first+i))
+		}
+		t, f := task{index: first + i}, ignoreCtx(f)
+		go func() {
+			defer g.wg.Done()
+			g.do(t, f)
+		}()
+	}
+}
+
+// GoWithMeta is like Go but attaches the metadata to the function.
+//
+// The metadata is returned along with the ok response by WaitMeta
+// and attributed to an error by GoroutineError.
+func (g *Group[T]) GoWithMeta(meta any, f func() (T, error)) {
+	g.start(task{meta: meta}, ignoreCtx(f))
+}
+
+// GoKeyed is like GoNamed but identifies the function by the key.
+//
+// If the group was configured with WithCache and the key is cached,
+// the function is not executed, the cached value is an ok response instead.
+// The cached value is recorded in a new goroutine as the function's would be,
+// subject to the limit and the circuit breaker.
+// Otherwise the function's ok response is cached under the key.
+func (g *Group[T]) GoKeyed(key string, f func() (T, error)) {
+	g.checkNotFrozen()
+	if g.pastDeadline() {
+		return
+	}
+	if g.cache == nil {
+		g.start(task{name: key}, ignoreCtx(f))
+		return
+	}
+	if v, ok := g.cache.Get(key); ok {
+		g.start(task{name: key}, func(context.Context) (T, error) { return v, nil })
+		return
+	}
+	g.start(task{name: key}, func(context.Context) (T, error) {
+		v, err := f()
+		if err == nil && (g.isOK == nil || g.isOK(v)) {
+			g.cache.Set(key, v)
+		}
+		return v, err
+	})
+}
+
+// start executes f in a new goroutine once it acquires the group's semaphore, if any.
+func (g *Group[T]) start(t task, f func(ctx context.Context) (T, error)) {
+	g.checkNotFrozen()
+	if g.pastDeadline() {
+		t.release()
+		return
+	}
+	g.checkAdmitted()
+	if g.sem != nil && !g.acquire() {
+		t.release()
+		return
+	}
+	g.launch(t, f)
+}
+
+// launch executes f in a new goroutine. The group's semaphore, if any,
+// must be acquired by the caller, it is released once f returns.
+func (g *Group[T]) launch(t task, f func(ctx context.Context) (T, error)) {
+	if g.circuitOpen() {
+		if g.sem != nil {
+			<-g.sem
+		}
+		t.index = g.next()
+		g.mu.Lock()
+		goerr := g.goroutineError(t, ErrCircuitOpen)
+		g.errs = append(g.errs, goerr)
+		g.failed++
+		g.track(Outcome[T]{Err: goerr, Index: t.index})
+		g.mu.Unlock()
+		g.metrics.IncErrored()
+		g.complete()
+		t.release()
+		return
+	}
+	t.index = g.next()
 	g.wg.Add(1)
-	go func() {
+	run := func() {
 		defer g.wg.Done()
-		ok, err := f()
-		if err != nil {
-			g.errCh <- err
-			return
+		if g.sem != nil {
+			defer func() { <-g.sem }()
 		}
-		select {
-		case g.okCh <- ok:
-			if g.cancel != nil {
-				g.cancel()
-			}
-		default:
+		g.do(t, f)
+	}
+	if g.pool == nil {
+		go run()
+		return
+	}
+	if err := g.pool.Submit(run); err != nil {
+		// The function cannot be dispatched, so it fails with the pool's error.
+		defer g.wg.Done()
+		if g.sem != nil {
+			defer func() { <-g.sem }()
 		}
-	}()
+		g.do(t, func(context.Context) (T, error) {
+			var ok T
+			return ok, err
+		})
+	}
 }
 
-// Wait blocks until all function calls from the Go method have returned.
+// ignoreCtx adapts f to a function accepting the goroutine's context.
+func ignoreCtx[T any](f func() (T, error)) func(context.Context) (T, error) {
+	return func(context.Context) (T, error) { return f() }
+}
+
+// next returns the 0-based index of a newly submitted goroutine.
+func (g *Group[T]) next() int {
+	g.metrics.IncSubmitted()
+	index := int(atomic.AddInt32(&g.submitted, 1)) - 1
+	if g.logger != nil {
+		g.log("okgroup: function submitted", slog.Int("index", index))
+	}
+	return index
+}
+
+// GoHedge executes a given function in a new goroutine and, if there is
+// no ok response after the delay, executes its backup copy in another goroutine.
 //
-// If there is an ok response then Wait returns the ok response and a nil error,
-// otherwise a T zero value is returned along with the group's error.
-func (g *Group[T]) Wait() (T, error) {
+// The function is passed the goroutine's context, so the copy which is still
+// running is canceled once the other one returns an ok response,
+// if the group was created by calling WithContext. The backup copy is not
+// executed if the group's context is done before the delay elapses.
+func (g *Group[T]) GoHedge(delay time.Duration, f func(ctx context.Context) (T, error)) {
+	g.checkNotFrozen()
+	if g.pastDeadline() {
+		// Neither the function nor its backup copy is executed.
+		return
+	}
+	g.start(task{}, f)
+	g.wg.Add(1)
 	go func() {
-		g.wg.Wait()
-		if g.cancel != nil {
-			g.cancel()
+		defer g.wg.Done()
+		c, stop := g.clock.NewTimer(delay)
+		defer stop()
+		select {
+		case <-c:
+			if g.Won() || g.ctx.Err() != nil || !g.admit() {
+				return
+			}
+			if g.sem != nil {
+				if !g.acquire() {
+					return
+				}
+				defer func() { <-g.sem }()
+			}
+			// The index is only taken by a backup copy which is executed,
+			// so a skipped copy is not counted as a function.
+			g.do(task{index: g.next()}, f)
+		case <-g.winCh:
+		case <-g.ctx.Done():
 		}
-		close(g.errCh)
 	}()
-	var grouperr Error
-	for err := range g.errCh {
-		grouperr.errors = append(grouperr.errors, err)
+}
+
+// acquire blocks until it acquires the group's semaphore or the group's
+// context is done. It reports whether the semaphore was acquired.
+func (g *Group[T]) acquire() bool {
+	if g.ctx.Err() != nil {
+		return false
 	}
 	select {
-	case ok := <-g.okCh:
-		return ok, nil
-	default:
-		var ok T
-		return ok, grouperr
+	case g.sem <- struct{}{}:
+		if g.ctx.Err() != nil {
+			<-g.sem
+			return false
+		}
+		return true
+	case <-g.ctx.Done():
+		return false
 	}
 }
+
+// Freeze prevents passing further functions to the group, without affecting
+// functions which are already running. Passing a function to a frozen group
+// panics, except for TryGo which returns ErrGroupFrozen.
+//
+// It allows the owner of the group to signal that all functions were passed
+// before handing the group over to a consumer calling Wait or Future.Get.
+func (g *Group[T]) Freeze() {
+	atomic.StoreInt32(&g.frozen, 1)
+	g.settle()
+}
+
+// IsFrozen reports whether Freeze was called.
+func (g *Group[T]) IsFrozen() bool {
+	return atomic.LoadInt32(&g.frozen) == 1
+}
+
+// Deadline sets the time after which functions passed to the group are silently
+// skipped, as if they were never passed. Functions passed before the deadline
+// are not affected.
+//
+// It allows producers passing functions in a loop to stop once their time budget
+// is spent without checking the clock. Skipped functions are not counted by Submitted,
+// TryGo returns nil for them.
+func (g *Group[T]) Deadline(t time.Time) {
+	g.submitDeadline.Store(t.UnixNano())
+}
+
+// pastDeadline reports whether the deadline set by Deadline has passed.
+func (g *Group[T]) pastDeadline() bool {
+	deadline := g.submitDeadline.Load()
+	return deadline != 0 && !g.clock.Now().Before(time.Unix(0, deadline))
+}
+
+// checkNotFrozen panics if the group is frozen.
+func (g *Group[T]) checkNotFrozen() {
+	if g.IsFrozen() {
+		panic("okgroup: function passed to a frozen group")
+	}
+}
+
+// admit reports whether a function fits under the cap configured
+// with WithMaxGoroutines and, if so, counts it against the cap.
+func (g *Group[T]) admit() bool {
+	return g.maxGoroutines <= 0 || atomic.AddInt32(&g.admitted, 1) <= g.maxGoroutines
+}
+
+// checkAdmitted panics if a function does not fit under the cap
+// configured with WithMaxGoroutines.
+func (g *Group[T]) checkAdmitted() {
+	if !g.admit() {
+		panic("okgroup: function passed to a full group")
+	}
+}
+
+// checkNotStarted panics if any function has been already passed to the group.
+// It guards configuration methods against mid-flight reconfiguration.
+func (g *Group[T]) checkNotStarted(method string) {
+	if atomic.LoadInt32(&g.submitted) > 0 {
+		panic("okgroup: " + method + " called after Go")
+	}
+}
+
+// do executes f and records its result.
+func (g *Group[T]) do(t task, f func(ctx context.Context) (T, error)) {
+	defer g.complete()
+	defer t.release()
+	ctx := g.ctx
+	if t.ctx != nil {
+		ctx = t.ctx
+	}
+	if g.spanner != nil {
+		var end func()
+		ctx, end = g.spanner(ctx, t.index, t.name)
+		defer end()
+	}
+	for _, key := range g.propagate {
+		if v := g.ctx.Value(key); v != nil {
+			ctx = context.WithValue(ctx, key, v)
+		}
+	}
+	start := g.clock.Now()
+	ok, err, swallowed := g.call(ctx, t, f)
+	if swallowed {
+		return
+	}
+	duration := g.clock.Now().Sub(start)
+	g.metrics.ObserveDuration(duration)
+	if g.logger != nil {
+		g.log("okgroup: function returned", slog.Int("index", t.index), slog.Duration("duration", duration))
+	}
+	for _, tap := range g.taps {
+		g.tap(tap, ok, err)
+	}
+	if err == nil && g.isOK != nil && !g.isOK(ok) {
+		err = ErrNotOK
+	}
+	if err != nil {
+		canceledByWin := errors.Is(err, context.Canceled) && g.Won() && !g.noCancelOnSuccess && g.score == nil
+		if canceledByWin || g.discardLateErrors && g.Won() {
+			// The function was canceled by the group itself because of
+			// an ok response or returned after it, it is not a failure worth reporting.
+			g.mu.Lock()
+			g.failed++
+			g.dropped = append(g.dropped, g.goroutineError(t, err))
+			g.mu.Unlock()
+			atomic.AddInt32(&g.discarded, 1)
+			return
+		}
+		if g.keepPartial && !isZero(ok) {
+			g.mu.Lock()
+			g.partials = append(g.partials, ok)
+			g.mu.Unlock()
+		}
+		if g.cancelOnFailure {
+			g.cancelByFailure(err)
+		}
+		goerr := g.goroutineError(t, err)
+		g.firstErr.CompareAndSwap(nil, &goerr)
+		g.mu.Lock()
+		retained := g.errBuffer <= 0 || len(g.errs) < g.errBuffer
+		if retained {
+			g.errs = append(g.errs, goerr)
+			g.track(Outcome[T]{Value: ok, Err: goerr, Index: t.index, Duration: duration})
+		}
+		g.failed++
+		g.failures++
+		g.lastFailure = g.clock.Now()
+		g.mu.Unlock()
+		g.metrics.IncErrored()
+		if g.logger != nil {
+			g.log("okgroup: function failed", slog.Int("index", t.index), slog.Duration("duration", duration), slog.Any("error", err))
+		}
+		g.checkQuorum()
+		if g.results != nil && retained {
+			g.send(Outcome[T]{Value: ok, Err: goerr, Index: t.index, Duration: duration})
+		}
+		return
+	}
+	g.mu.Lock()
+	g.oks = append(g.oks, okResponse[T]{index: t.index, meta: t.meta, value: ok, duration: duration})
+	won := len(g.oks) == g.quorum
+	winner := g.oks[0]
+	g.track(Outcome[T]{Value: ok, Index: t.index, Duration: duration})
+	if g.streamNotify != nil {
+		select {
+		case g.streamNotify <- struct{}{}:
+		default:
+		}
+	}
+	g.mu.Unlock()
+	if won && g.score == nil {
+		g.win(winner, t.index, duration)
+	}
+	if g.results != nil {
+		g.send(Outcome[T]{Value: ok, Index: t.index, Duration: duration})
+	}
+}
+
+// win declares the group's winner. index and duration are the ones
+// of the function whose ok response reached the quorum.
+func (g *Group[T]) win(winner okResponse[T], index int, duration time.Duration) {
+	g.winner.Store(&winner)
+	close(g.winCh)
+	g.metrics.SetWinner()
+	if g.logger != nil {
+		g.log("okgroup: group won", slog.Int("index", index), slog.Duration("duration", duration))
+	}
+	if !g.noCancelOnSuccess && g.score == nil {
+		g.stopWith(ErrWon)
+	}
+	if g.onWin != nil {
+		g.onWin(winner.value)
+	}
+}
+
+// winScored declares the ok response with the highest score the winner
+// of a group configured with SetScore, if the quorum was reached.
+// It must be called once all functions have returned, as the winner is final.
+func (g *Group[T]) winScored() {
+	if g.score == nil {
+		return
+	}
+	g.scoreOnce.Do(func() {
+		if best := g.best(); best != nil {
+			g.win(*best, best.index, best.duration)
+		}
+	})
+}
+
+// call calls f, recovering its panic, if any.
+// It reports whether the panic was swallowed by the callback configured with OnPanic.
+func (g *Group[T]) call(ctx context.Context, t task, f func(ctx context.Context) (T, error)) (ok T, err error, swallowed bool) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		var zero T
+		ok = zero
+		if g.onPanic == nil {
+			err = PanicError{Value: r, Stack: debug.Stack()}
+			return
+		}
+		err = g.onPanic(t.index, r)
+		swallowed = err == nil
+	}()
+	ok, err = f(ctx)
+	return ok, err, false
+}
+
+// goroutineError attributes err to the goroutine, annotating it with
+// the goroutine's index if the group was configured with WithIndexedErrors.
+func (g *Group[T]) goroutineError(t task, err error) error {
+	goerr := GoroutineError{Index: t.index, Name: t.name, Meta: t.meta, Err: err}
+	if g.indexedErrors {
+		return fmt.Errorf("okgroup[%d]: %w", t.index, goerr)
+	}
+	return goerr
+}
+
+// track records the outcome for Seq2, if it is in use. It must be called with mu held.
+func (g *Group[T]) track(outcome Outcome[T]) {
+	if g.outcomesCond != nil {
+		g.outcomes = append(g.outcomes, outcome)
+		g.outcomesCond.Broadcast()
+	}
+}
+
+// circuitOpen reports whether the circuit breaker configured with
+// WithCircuitBreaker is open. The breaker resets once the reset timeout
+// has elapsed since the last failure.
+func (g *Group[T]) circuitOpen() bool {
+	if g.breakerThreshold <= 0 {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.failures < g.breakerThreshold {
+		return false
+	}
+	if g.clock.Now().Sub(g.lastFailure) >= g.breakerReset {
+		g.failures = 0
+		return false
+	}
+	return true
+}
+
+// tap invokes the observer, discarding its panic, if any.
+func (g *Group[T]) tap(fn func(T, error), ok T, err error) {
+	defer func() {
+		_ = recover()
+	}()
+	fn(ok, err)
+}
+
+// complete counts a completed function call and cancels the group's context
+// if the number of completions configured with CancelAfterN is reached.
+func (g *Group[T]) complete() {
+	n := atomic.AddInt32(&g.completed, 1)
+	g.metrics.IncCompleted()
+	if n == atomic.LoadInt32(&g.cancelAt) {
+		g.stop()
+	}
+	if g.progress != nil {
+		select {
+		case g.progress <- float64(n) / float64(atomic.LoadInt32(&g.submitted)):
+		default:
+		}
+	}
+	g.settle()
+}
+
+// settle closes settledCh if the group is frozen and all its functions have returned.
+func (g *Group[T]) settle() {
+	if g.IsFrozen() && atomic.LoadInt32(&g.completed) == atomic.LoadInt32(&g.submitted) {
+		g.winScored()
+		g.settleOnce.Do(func() { close(g.settledCh) })
+	}
+}
+
+// cancelByFailure cancels the group's context because of err,
+// if the context has not been canceled by a failure yet.
+func (g *Group[T]) cancelByFailure(err error) {
+	g.mu.Lock()
+	first := g.cause == nil
+	if first {
+		g.cause = err
+	}
+	g.mu.Unlock()
+	if first {
+		g.stopWith(err)
+	}
+}
+
+// stop cancels the group's context, if any,
+// and invokes the OnCancel callback the first time it is called.
+func (g *Group[T]) stop() {
+	g.stopWith(nil)
+}
+
+// stopWith is like stop but cancels the group's context with the cause,
+// unless the context is already canceled.
+func (g *Group[T]) stopWith(cause error) {
+	if g.cancel != nil {
+		g.cancel(cause)
+	}
+	g.stopOnce.Do(func() {
+		if g.onCancel != nil {
+			g.onCancel()
+		}
+	})
+}
+
+// Wait blocks until all function calls from the Go method have returned.
+//
+// If the group's context has a deadline, Wait returns once the deadline
+// is exceeded even if some functions have not returned yet, they are left
+// to finish in the background. context.DeadlineExceeded is then part
+// of the group's error.
+//
+// If there is an ok response then Wait returns the ok response and a nil error,
+// otherwise a T zero value is returned along with the group's error,
+// or ErrNoGoroutines if no function was passed to the group.
+// If all functions failed because the group's context was canceled,
+// the group's error matches ErrGroupCanceled.
+// If the group was configured with WithDefault, the fallback value
+// and a nil error are returned instead.
+func (g *Group[T]) Wait() (T, error) {
+	g.wait()
+	if winner := g.best(); winner != nil {
+		return winner.value, nil
+	}
+	if g.fallback != nil {
+		return *g.fallback, nil
+	}
+	var ok T
+	if atomic.LoadInt32(&g.submitted) == 0 {
+		return ok, ErrNoGoroutines
+	}
+	err := g.err()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.oks) > 0 || g.quorum > 1 {
+		err.errors = append(err.errors, ErrNoQuorum)
+	}
+	if err.canceled() {
+		err.msg = ErrGroupCanceled.Error()
+	}
+	return ok, err
+}
+
+// WaitMeta is like Wait but returns the ok response along with
+// the metadata attached to the function by GoWithMeta.
+func (g *Group[T]) WaitMeta() (MetaResult[T], error) {
+	ok, err := g.Wait()
+	if winner := g.best(); winner != nil {
+		return MetaResult[T]{Value: winner.value, Meta: winner.meta}, nil
+	}
+	return MetaResult[T]{Value: ok}, err
+}
+
+// WaitMust is like Wait but panics if the group fails.
+//
+// It is intended for use in tests and initialisation paths.
+func (g *Group[T]) WaitMust() T {
+	ok, err := g.Wait()
+	if err != nil {
+		if g.name != "" {
+			err = fmt.Errorf("%s: %w", g.id(), err)
+		}
+		panic(err)
+	}
+	return ok
+}
+
+// WaitChan is like Wait but delivers the result over the returned channel,
+// which allows to wait for the group in a select statement.
+//
+// The channel delivers exactly one Outcome once the group completes and is then closed.
+// Subsequent calls return the same channel.
+func (g *Group[T]) WaitChan() <-chan Outcome[T] {
+	g.waitChOnce.Do(func() {
+		ch := make(chan Outcome[T], 1)
+		g.waitCh = ch
+		go func() {
+			ok, err := g.Wait()
+			ch <- Outcome[T]{Value: ok, Err: err}
+			close(ch)
+		}()
+	})
+	return g.waitCh
+}
+
+// WaitStream is like Wait but does not block, instead it delivers ok responses
+// over the returned channel in the order they were returned by functions.
+// Ok responses returned before the call are delivered first.
+//
+// The channel is closed once all function calls from the Go method have returned.
+// Functions are not blocked by the channel, but it has to be drained
+// for WaitStreamErr to return. Subsequent calls return the same channel.
+func (g *Group[T]) WaitStream() <-chan T {
+	g.streamOnce.Do(func() {
+		ch := make(chan T)
+		notify := make(chan struct{}, 1)
+		g.mu.Lock()
+		g.stream, g.streamNotify = ch, notify
+		g.mu.Unlock()
+		done := make(chan struct{})
+		go func() {
+			_, err := g.Wait()
+			if err == nil {
+				if e := g.err(); len(e.errors) > 0 {
+					err = e
+				}
+			}
+			g.streamErr = err
+			close(done)
+		}()
+		go func() {
+			sent := 0
+			for {
+				var closing bool
+				select {
+				case <-notify:
+				case <-done:
+					closing = true
+				}
+				g.mu.Lock()
+				pending := make([]T, 0, len(g.oks)-sent)
+				for _, ok := range g.oks[sent:] {
+					pending = append(pending, ok.value)
+				}
+				sent = len(g.oks)
+				g.mu.Unlock()
+				for _, ok := range pending {
+					ch <- ok
+				}
+				if closing {
+					close(ch)
+					return
+				}
+			}
+		}()
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+	})
+	return g.stream
+}
+
+// WaitStreamErr returns the error of the group streamed by WaitStream.
+// If the group has an ok response, the error holds errors of functions
+// which failed, if any.
+//
+// WaitStreamErr must be called after the channel returned by WaitStream is closed.
+func (g *Group[T]) WaitStreamErr() error {
+	return g.streamErr
+}
+
+// A Summary is the result of a Group along with the statistics of its execution.
+type Summary[T any] struct {
+	Value T
+	Err   error
+	// Duration is the time elapsed from the group's creation until Wait returned.
+	Duration time.Duration
+	// Submitted is the number of functions passed to the group.
+	Submitted int
+	// Succeeded is the number of functions which returned an ok response.
+	Succeeded int
+	// Failed is the number of functions which returned an error.
+	Failed int
+	// Errors are errors retained by the group.
+	Errors []error
+}
+
+// WaitSummary is like Wait but returns the result along with
+// the statistics of the group's execution.
+func (g *Group[T]) WaitSummary() Summary[T] {
+	ok, err := g.Wait()
+	duration := g.clock.Now().Sub(g.created)
+	snapshot := g.Snapshot()
+	return Summary[T]{
+		Value:     ok,
+		Err:       err,
+		Duration:  duration,
+		Submitted: snapshot.Submitted,
+		Succeeded: snapshot.Succeeded,
+		Failed:    snapshot.Failed,
+		Errors:    snapshot.PendingErrors,
+	}
+}
+
+// A Future is the result of a Group which may not be available yet.
+type Future[T any] struct {
+	g *Group[T]
+}
+
+// Future returns the Future of the group's result.
+//
+// It allows to pass the result to a consumer which does not manage the group.
+func (g *Group[T]) Future() *Future[T] {
+	return &Future[T]{g: g}
+}
+
+// Get blocks until the group has an ok response and returns it.
+//
+// If the group fails, Get returns the same result as Wait once the group completes,
+// that is once the group's owner calls Wait or Close, or once all functions
+// of a frozen group have returned. Unless the owner freezes the group
+// by calling Freeze after passing all functions, Get called on a failing group
+// blocks until the owner waits for it.
+func (f *Future[T]) Get() (T, error) {
+	g := f.g
+	select {
+	case <-g.winCh:
+		return g.best().value, nil
+	case <-g.doneCh:
+	case <-g.settledCh:
+	}
+	return g.Wait()
+}
+
+// WaitWithTimeout is like Wait but returns once the timeout d expires,
+// even if some functions have not returned yet.
+//
+// If the timeout expires and the group has an ok response, it is returned.
+// Otherwise the group's context is canceled, the remaining functions are left
+// to finish in the background and a T zero value is returned along with
+// an error wrapping ErrGroupTimeout.
+func (g *Group[T]) WaitWithTimeout(d time.Duration) (T, error) {
+	done := make(chan struct{})
+	go func() {
+		g.wait()
+		close(done)
+	}()
+	c, stop := g.clock.NewTimer(d)
+	defer stop()
+	select {
+	case <-done:
+		return g.Wait()
+	case <-c:
+	}
+	if winner := g.best(); winner != nil {
+		return winner.value, nil
+	}
+	g.stopWith(ErrGroupTimeout)
+	var ok T
+	return ok, fmt.Errorf("%w: after %v", ErrGroupTimeout, d)
+}
+
+// WaitUntil is like WaitWithTimeout but returns once the deadline expires.
+func (g *Group[T]) WaitUntil(deadline time.Time) (T, error) {
+	return g.WaitWithTimeout(deadline.Sub(g.clock.Now()))
+}
+
+// WaitWithFallback is like Wait but returns the fallback value if the group fails.
+//
+// The group's error is discarded, use Wait to inspect it.
+// The fallback value takes precedence over the one configured with WithDefault.
+func (g *Group[T]) WaitWithFallback(fallback T) T {
+	ok, err := g.Wait()
+	if err != nil || !g.Won() {
+		return fallback
+	}
+	return ok
+}
+
+// WaitThenDrain blocks until there is an ok response or all function calls
+// from the Go method have returned.
+//
+// Unlike Wait, WaitThenDrain returns the ok response as soon as it is available
+// and lets the remaining goroutines finish in the background. The returned
+// channel is closed once all goroutines have returned, receiving from it
+// is optional. Results are returned as by Wait.
+func (g *Group[T]) WaitThenDrain() (T, error, <-chan struct{}) {
+	waited, drained := make(chan struct{}), make(chan struct{})
+	go func() {
+		g.wait()
+		close(waited)
+		g.wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-g.winCh:
+		return g.best().value, nil, drained
+	case <-waited:
+		ok, err := g.Wait()
+		return ok, err, drained
+	}
+}
+
+// WaitAll blocks until all function calls from the Go method have returned.
+//
+// WaitAll returns outcomes of all functions which returned an ok response
+// in the order they were produced, along with the functions' indexes and durations.
+// If any function failed, the group's error is returned along with them.
+// Note that the group's context is still canceled by the first ok response,
+// if the group was created by calling WithContext without WithNoCancelOnSuccess.
+func (g *Group[T]) WaitAll() ([]Outcome[T], error) {
+	g.wait()
+	g.mu.Lock()
+	oks := make([]Outcome[T], len(g.oks))
+	for i, ok := range g.oks {
+		oks[i] = Outcome[T]{Value: ok.value, Index: ok.index, Duration: ok.duration}
+	}
+	g.mu.Unlock()
+	if err := g.err(); len(err.errors) > 0 {
+		return oks, err
+	}
+	return oks, nil
+}
+
+// Seq returns an iterator over ok responses of the group.
+//
+// The iterator calls WaitAll and yields ok responses in the order they were produced.
+// Errors are not yielded, use Err after the iteration to check whether any function failed.
+func (g *Group[T]) Seq() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		oks, _ := g.WaitAll()
+		for _, ok := range oks {
+			if !yield(ok.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Seq2 returns an iterator over outcomes of functions as they return,
+// yielding the ok response or the error of each function.
+//
+// Outcomes of functions which returned before the iteration started are yielded first,
+// ok responses followed by errors. The iterator calls Wait and ends once Wait has returned.
+// As with Results, errors of functions canceled because of an ok response are not yielded,
+// nor are errors dropped because of WithErrorBuffer.
+func (g *Group[T]) Seq2() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		g.mu.Lock()
+		if g.outcomesCond == nil {
+			for _, ok := range g.oks {
+				g.outcomes = append(g.outcomes, Outcome[T]{Value: ok.value, Index: ok.index, Duration: ok.duration})
+			}
+			for _, err := range g.errs {
+				outcome := Outcome[T]{Err: err}
+				var goerr GoroutineError
+				if errors.As(err, &goerr) {
+					outcome.Index = goerr.Index
+				}
+				g.outcomes = append(g.outcomes, outcome)
+			}
+			g.outcomesCond = sync.NewCond(&g.mu)
+		}
+		g.mu.Unlock()
+		go g.wait()
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		for sent := 0; ; {
+			for sent < len(g.outcomes) {
+				outcome := g.outcomes[sent]
+				sent++
+				g.mu.Unlock()
+				more := yield(outcome.Value, outcome.Err)
+				g.mu.Lock()
+				if !more {
+					return
+				}
+			}
+			select {
+			case <-g.doneCh:
+				return
+			default:
+			}
+			g.outcomesCond.Wait()
+		}
+	}
+}
+
+// Err returns the group's error containing errors of functions
+// which failed so far, or nil if no function has failed.
+//
+// Unlike Wait, Err does not block and returns the errors even if the group
+// has an ok response. It is intended to be called after iterating over Seq.
+func (g *Group[T]) Err() error {
+	if err := g.err(); len(err.errors) > 0 {
+		return err
+	}
+	return nil
+}
+
+// WaitAllOrdered is like WaitAll but returns ok responses in the order
+// the functions were passed to the group.
+//
+// The returned slice is indexed by the goroutine's index, a T zero value
+// is placed at the index of each function which failed. Unlike Wait and WaitAll,
+// WaitAllOrdered keeps errors of functions canceled because of the first ok response,
+// or discarded because of WithDiscardLateErrors, in the returned error, so every
+// zero value placed for a failed function is accounted for. Use WithNoCancelOnSuccess
+// to prevent canceling functions by the first ok response.
+func (g *Group[T]) WaitAllOrdered() ([]T, error) {
+	g.wait()
+	err := g.err()
+	g.mu.Lock()
+	oks := make([]T, atomic.LoadInt32(&g.submitted))
+	for _, ok := range g.oks {
+		oks[ok.index] = ok.value
+	}
+	err.errors = append(err.errors, g.dropped...)
+	g.mu.Unlock()
+	if len(err.errors) > 0 {
+		return oks, err
+	}
+	return oks, nil
+}
+
+// Results returns a channel delivering the outcome of each function
+// as soon as it returns, if the group was configured with WithResults.
+// Otherwise Results returns nil.
+//
+// The outcome of a failed function carries the function's error, errors of functions
+// canceled because of an ok response are not delivered, as they are not part of the group's error.
+// The channel is closed once Wait or Close has waited for all functions.
+// Functions block until their outcomes are received or the buffer configured
+// with WithResultBuffer has room, so the channel has to be drained for the group to complete.
+// If the group's context is canceled other than because of an ok response, outcomes
+// which cannot be delivered without blocking are dropped.
+func (g *Group[T]) Results() <-chan Outcome[T] {
+	return g.results
+}
+
+// send delivers the outcome over the channel returned by Results.
+func (g *Group[T]) send(outcome Outcome[T]) {
+	select {
+	case g.results <- outcome:
+		return
+	default:
+	}
+	select {
+	case g.results <- outcome:
+		return
+	case <-g.ctx.Done():
+	}
+	if context.Cause(g.ctx) == ErrWon {
+		// The consumer is still expected to drain the channel.
+		g.results <- outcome
+	}
+}
+
+// CancelAfter cancels the group's context after the duration d.
+//
+// Calling CancelAfter again replaces the previous timer. CancelAfter is a no-op
+// if the group's context is already canceled or the group was created by calling New.
+func (g *Group[T]) CancelAfter(d time.Duration) {
+	if g.cancel == nil || g.ctx.Err() != nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.stopCancelTimer != nil {
+		g.stopCancelTimer()
+	}
+	g.stopCancelTimer = g.clock.AfterFunc(d, g.stop)
+}
+
+// CancelAfterN cancels the group's context once n function calls
+// from the Go method have returned, regardless of their results.
+//
+// If n is greater than the number of functions passed to the group,
+// the context is never canceled by CancelAfterN.
+func (g *Group[T]) CancelAfterN(n int) {
+	atomic.StoreInt32(&g.cancelAt, int32(n))
+	if n > 0 && atomic.LoadInt32(&g.completed) >= int32(n) {
+		g.stop()
+	}
+}
+
+// Close cancels the group's context and blocks until all function calls
+// from the Go method have returned, discarding their results.
+//
+// Close is safe to call multiple times and regardless of whether Wait was called.
+func (g *Group[T]) Close() {
+	g.stop()
+	g.wait()
+	g.wg.Wait()
+}
+
+// Reset closes the group and returns it to the state of a new group
+// derived from ctx, as if it was created by calling WithContext
+// with the group's options. It returns the derived Context.
+//
+// Reset discards all results of the group but keeps its configuration,
+// including the one set by setters such as OnWin. It allows to reuse
+// groups through a sync.Pool: a group put to the pool after Wait
+// can be taken from the pool and reset before passing new functions to it.
+// Reset must not be called concurrently with other methods.
+func (g *Group[T]) Reset(ctx context.Context) context.Context {
+	g.Close()
+	if g.waited != nil {
+		<-g.waited
+		g.waited = nil
+	}
+	g.newCtx = func() (context.Context, context.CancelCauseFunc) {
+		return context.WithCancelCause(ctx)
+	}
+	g.parent, g.stopCh = ctx, nil
+	g.ctx, g.cancel = g.newCtx()
+	ctx = g.ctx
+	g.created = g.clock.Now()
+	atomic.StoreInt32(&g.submitted, 0)
+	atomic.StoreInt32(&g.frozen, 0)
+	atomic.StoreInt32(&g.admitted, 0)
+	atomic.StoreInt32(&g.discarded, 0)
+	g.submitDeadline.Store(0)
+	atomic.StoreInt32(&g.completed, 0)
+	atomic.StoreInt32(&g.cancelAt, 0)
+	g.mu.Lock()
+	g.oks, g.errs, g.dropped, g.partials = nil, nil, nil, nil
+	g.failed, g.failures = 0, 0
+	g.lastFailure = time.Time{}
+	g.cause = nil
+	g.mu.Unlock()
+	g.winner.Store(nil)
+	g.firstErr.Store(nil)
+	g.winCh = make(chan struct{})
+	g.stopCancelTimer = nil
+	g.stopOnce = sync.Once{}
+	g.waitOnce = sync.Once{}
+	g.waitChOnce = sync.Once{}
+	g.waitCh = nil
+	g.streamOnce = sync.Once{}
+	g.stream, g.streamNotify, g.streamErr = nil, nil, nil
+	g.outcomes, g.outcomesCond = nil, nil
+	atomic.StoreInt32(&g.waiting, 0)
+	g.lostCh = make(chan struct{})
+	g.doneCh = make(chan struct{})
+	g.settledCh = make(chan struct{})
+	g.settleOnce = sync.Once{}
+	g.scoreOnce = sync.Once{}
+	if g.results != nil {
+		g.results = make(chan Outcome[T], cap(g.results))
+	}
+	g.lostOnce = sync.Once{}
+	return ctx
+}
+
+// Submitted returns the number of functions passed to the group so far.
+//
+// It is safe to call Submitted concurrently with passing functions to the group.
+// Functions skipped because the group's context was done while waiting
+// for the limit configured with WithLimit are not counted.
+func (g *Group[T]) Submitted() int {
+	return int(atomic.LoadInt32(&g.submitted))
+}
+
+// Won reports whether any function passed to Go returned an ok response,
+// or whether the quorum was reached if the group was configured with WithQuorum.
+//
+// Won is only meaningful after Wait has returned, it allows to tell
+// a degraded success apart from a complete failure.
+func (g *Group[T]) Won() bool {
+	return g.winner.Load() != nil
+}
+
+// TryResult returns the group's ok response and true if there is one,
+// otherwise a T zero value and false. Unlike Wait, it does not block.
+//
+// It allows to poll for an early ok response before committing to Wait
+// and is safe to call concurrently with passing functions to the group.
+func (g *Group[T]) TryResult() (T, bool) {
+	if winner := g.best(); winner != nil {
+		return winner.value, true
+	}
+	var ok T
+	return ok, false
+}
+
+// Succeeded returns the number of functions which returned an ok response so far.
+//
+// Once Wait has returned, Succeeded and Failed add up to Submitted,
+// unless a panic was swallowed by the callback configured with OnPanic.
+func (g *Group[T]) Succeeded() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.oks)
+}
+
+// Discarded returns the number of functions whose errors were discarded
+// because they returned after the group had an ok response, either canceled
+// by the ok response or configured with WithDiscardLateErrors.
+//
+// It is a measure of work wasted by racing functions.
+func (g *Group[T]) Discarded() int {
+	return int(atomic.LoadInt32(&g.discarded))
+}
+
+// Failed returns the number of functions which failed so far, including
+// those canceled because of an ok response whose errors are not part of the group's error.
+func (g *Group[T]) Failed() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.failed
+}
+
+// String returns the group's identifier, including the name configured
+// with WithName, along with a summary of the group's state for debugging.
+//
+// It is safe to call String concurrently with running goroutines.
+func (g *Group[T]) String() string {
+	return fmt.Sprintf("%s{submitted: %d, completed: %d, won: %t, canceled: %t}",
+		g.id(), atomic.LoadInt32(&g.submitted), atomic.LoadInt32(&g.completed), g.Won(), g.ctx.Err() != nil)
+}
+
+// id returns the group's identifier in the form okgroup.Group[T](name).
+func (g *Group[T]) id() string {
+	id := "okgroup.Group[" + reflect.TypeOf((*T)(nil)).Elem().String() + "]"
+	if g.name != "" {
+		id += "(" + g.name + ")"
+	}
+	return id
+}
+
+// Partials returns non-zero values returned along with a non-nil error
+// in the order they were produced. It is only meaningful after Wait has returned
+// and if the group was configured by calling SetKeepPartial.
+func (g *Group[T]) Partials() []T {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	partials := make([]T, len(g.partials))
+	copy(partials, g.partials)
+	return partials
+}
+
+// Errors returns a snapshot of errors returned by functions passed to the group so far.
+//
+// Unlike Wait, Errors does not block, so it allows to observe failures
+// of a long-lived group while functions are still being passed to it.
+func (g *Group[T]) Errors() []error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	errs := make([]error, len(g.errs))
+	copy(errs, g.errs)
+	return errs
+}
+
+// FirstError returns the error of the earliest completing function
+// that failed, or nil if no function has failed yet.
+//
+// FirstError does not block, it is safe to call it while functions are still running.
+// The error is kept even if it was dropped because of WithErrorBuffer.
+func (g *Group[T]) FirstError() error {
+	if err := g.firstErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// A GroupSnapshot describes the state of a Group at a point in time.
+type GroupSnapshot struct {
+	// Submitted is the number of functions passed to the group.
+	Submitted int
+	// Running is the number of functions which have not returned yet.
+	Running int
+	// Succeeded is the number of functions which returned an ok response.
+	Succeeded int
+	// Failed is the number of functions which returned an error.
+	Failed int
+	// PendingErrors are errors retained by the group so far.
+	PendingErrors []error
+	// HasResult reports whether the group has an ok response to return from Wait.
+	HasResult bool
+}
+
+// Snapshot returns the current state of the group for diagnostics.
+//
+// Snapshot does not block and does not affect the group,
+// it is safe to call it while functions are still running.
+func (g *Group[T]) Snapshot() GroupSnapshot {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	completed := atomic.LoadInt32(&g.completed)
+	submitted := atomic.LoadInt32(&g.submitted)
+	errs := make([]error, len(g.errs))
+	copy(errs, g.errs)
+	return GroupSnapshot{
+		Submitted:     int(submitted),
+		Running:       int(submitted - completed),
+		Succeeded:     len(g.oks),
+		Failed:        g.failed,
+		PendingErrors: errs,
+		HasResult:     g.Won(),
+	}
+}
+
+// wait blocks until all goroutines have returned or the deadline
+// of the group's context is exceeded.
+// Only the first call waits for the group, subsequent calls return immediately.
+func (g *Group[T]) wait() {
+	g.waitOnce.Do(func() {
+		var deadline <-chan time.Time
+		if d, ok := g.ctx.Deadline(); ok {
+			c, stop := g.clock.NewTimer(d.Sub(g.clock.Now()))
+			defer stop()
+			deadline = c
+		}
+		atomic.StoreInt32(&g.waiting, 1)
+		g.checkQuorum()
+		if deadline == nil && g.quorum <= 1 {
+			g.wg.Wait()
+		} else {
+			g.waitBounded(deadline)
+		}
+		g.winScored()
+		g.stop()
+		g.mu.Lock()
+		if g.stopCancelTimer != nil {
+			g.stopCancelTimer()
+		}
+		g.mu.Unlock()
+		close(g.doneCh)
+		g.mu.Lock()
+		if g.outcomesCond != nil {
+			g.outcomesCond.Broadcast()
+		}
+		g.mu.Unlock()
+		if g.logger != nil {
+			snapshot := g.Snapshot()
+			g.log("okgroup: group waited", slog.Int("submitted", snapshot.Submitted), slog.Int("succeeded", snapshot.Succeeded), slog.Int("failed", snapshot.Failed))
+		}
+		if g.results != nil {
+			results, waited := g.results, g.waited
+			if waited == nil {
+				close(results)
+			} else {
+				// Some functions may still be running, the channel is closed once they return.
+				go func() {
+					<-waited
+					close(results)
+				}()
+			}
+		}
+	})
+}
+
+// quorumLost reports whether the quorum can no longer be reached,
+// because too few functions are left to return an ok response.
+// It is only meaningful once all functions have been passed to the group.
+func (g *Group[T]) quorumLost() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return int(atomic.LoadInt32(&g.submitted))-g.failed < g.quorum
+}
+
+// checkQuorum signals Wait to return early if the quorum can no longer be reached.
+func (g *Group[T]) checkQuorum() {
+	if g.quorum > 1 && atomic.LoadInt32(&g.waiting) == 1 && g.quorumLost() {
+		g.lostOnce.Do(func() { close(g.lostCh) })
+	}
+}
+
+// waitBounded blocks until all goroutines have returned, the deadline
+// or the quorum can no longer be reached.
+// Functions ignoring the deadline are left to finish in the background.
+func (g *Group[T]) waitBounded(deadline <-chan time.Time) {
+	done := make(chan struct{})
+	g.waited = done
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-g.lostCh:
+	case <-deadline:
+		select {
+		case <-done:
+		default:
+			g.mu.Lock()
+			g.errs = append(g.errs, context.DeadlineExceeded)
+			g.mu.Unlock()
+		}
+		// The deadline is the context's one, wait for the context to expire
+		// so it is not canceled by Wait before, which would hide the cause.
+		<-g.ctx.Done()
+	}
+}
+
+// best returns the group's winner or, if the group was configured with SetScore,
+// the ok response with the highest score so far once the quorum is reached.
+// It returns nil if there is no such response.
+func (g *Group[T]) best() *okResponse[T] {
+	if g.score == nil {
+		return g.winner.Load()
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.oks) == 0 || len(g.oks) < g.quorum {
+		return nil
+	}
+	best, max := g.oks[0], g.score(g.oks[0].value)
+	for _, ok := range g.oks[1:] {
+		if score := g.score(ok.value); score > max {
+			best, max = ok, score
+		}
+	}
+	return &best
+}
+
+// err returns the group's error built from the collected errors.
+func (g *Group[T]) err() Error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	errs := make([]error, len(g.errs))
+	copy(errs, g.errs)
+	return Error{errors: errs, cause: g.cause, partial: len(g.oks) > 0}
+}
+
+// isZero reports whether v is a T zero value.
+func isZero[T any](v T) bool {
+	return reflect.ValueOf(&v).Elem().IsZero()
+}
@@ -3,6 +3,13 @@ package okgroup
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -78,3 +85,2023 @@ func TestWait_WithContext(t *testing.T) {
 		}
 	}
 }
+
+func TestWon(t *testing.T) {
+	tests := []struct {
+		name      string
+		executors []Executor
+		want      bool
+	}{
+		{
+			name: "partial success",
+			executors: []Executor{
+				{fn: func() (Result, error) { return "", errors.New("executor_1 failed") }},
+				{fn: func() (Result, error) { return "executor_2", nil }},
+			},
+			want: true,
+		},
+		{
+			name: "all failures",
+			executors: []Executor{
+				{fn: func() (Result, error) { return "", errors.New("executor_1 failed") }},
+				{fn: func() (Result, error) { return "", errors.New("executor_2 failed") }},
+			},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		g, _ := WithContext[Result](context.Background())
+		for _, executor := range tc.executors {
+			g.Go(executor.Execute)
+		}
+		g.Wait()
+		if got := g.Won(); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPartials(t *testing.T) {
+	errPartial := errors.New("partial response")
+	tests := []struct {
+		name string
+		keep bool
+		want []Result
+	}{
+		{name: "keep partial", keep: true, want: []Result{"partial"}},
+		{name: "discard partial", keep: false, want: []Result{}},
+	}
+	for _, tc := range tests {
+		g, _ := WithContext[Result](context.Background())
+		g.SetKeepPartial(tc.keep)
+		g.Go(func() (Result, error) { return "partial", errPartial })
+		g.Go(func() (Result, error) { return "", errors.New("executor_2 failed") })
+		_, err := g.Wait()
+		if !errors.Is(err, errPartial) {
+			t.Errorf("%s: got err %v, want err %v", tc.name, err, errPartial)
+		}
+		got := g.Partials()
+		if len(got) != len(tc.want) {
+			t.Fatalf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestWait_SingleGo(t *testing.T) {
+	errFailed := errors.New("executor_1 failed")
+	tests := []struct {
+		name    string
+		fn      func() (Result, error)
+		want    Result
+		wanterr error
+	}{
+		{name: "ok response", fn: func() (Result, error) { return "executor_1", nil }, want: "executor_1"},
+		{name: "error", fn: func() (Result, error) { return "", errFailed }, wanterr: errFailed},
+	}
+	for _, tc := range tests {
+		g, ctx := WithContext[Result](context.Background())
+		g.Go(tc.fn)
+		got, err := g.Wait()
+		if tc.wanterr == nil && err != nil {
+			t.Errorf("%s: want nil err, got %v", tc.name, err)
+		}
+		if tc.wanterr != nil && !errors.Is(err, tc.wanterr) {
+			t.Errorf("%s: got err %v, want err %v", tc.name, err, tc.wanterr)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+		select {
+		case <-ctx.Done():
+		default:
+			t.Errorf("%s: want ctx canceled", tc.name)
+		}
+	}
+}
+
+func BenchmarkWait_SingleGo(b *testing.B) {
+	fn := func() (Result, error) { return "", errors.New("executor_1 failed") }
+	for i := 0; i < b.N; i++ {
+		g, _ := WithContext[Result](context.Background())
+		g.Go(fn)
+		g.Wait()
+	}
+}
+
+func TestGoHedge(t *testing.T) {
+	tests := []struct {
+		name      string
+		primary   time.Duration
+		want      Result
+		wantCalls int32
+	}{
+		{name: "fast primary", primary: 0, want: "primary", wantCalls: 1},
+		{name: "slow primary", primary: time.Second, want: "backup", wantCalls: 2},
+	}
+	for _, tc := range tests {
+		var calls int32
+		g, _ := WithContext[Result](context.Background())
+		g.GoHedge(time.Millisecond*50, func(ctx context.Context) (Result, error) {
+			if atomic.AddInt32(&calls, 1) > 1 {
+				return "backup", nil
+			}
+			select {
+			case <-time.After(tc.primary):
+				return "primary", nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		})
+		got, err := g.Wait()
+		if err != nil {
+			t.Fatalf("%s: want nil err, got %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+		if calls := atomic.LoadInt32(&calls); calls != tc.wantCalls {
+			t.Errorf("%s: got %d calls, want %d", tc.name, calls, tc.wantCalls)
+		}
+		snapshot := g.Snapshot()
+		if snapshot.Submitted != int(tc.wantCalls) || snapshot.Running != 0 {
+			t.Errorf("%s: got %+v, want %d submitted and none running", tc.name, snapshot, tc.wantCalls)
+		}
+		if got := g.Succeeded() + g.Failed(); got != g.Submitted() {
+			t.Errorf("%s: got %d succeeded and failed, want %d submitted", tc.name, got, g.Submitted())
+		}
+		Clone(g)
+	}
+}
+
+func TestGoHedge_Canceled(t *testing.T) {
+	tests := []struct {
+		name   string
+		cancel func(g *Group[Result], cancel context.CancelFunc)
+	}{
+		{name: "close", cancel: func(g *Group[Result], _ context.CancelFunc) { g.Close() }},
+		{name: "parent canceled", cancel: func(g *Group[Result], cancel context.CancelFunc) {
+			cancel()
+			g.Wait()
+		}},
+	}
+	for _, tc := range tests {
+		var calls int32
+		ctx, cancel := context.WithCancel(context.Background())
+		g, _ := WithContext[Result](ctx)
+		g.GoHedge(time.Second*2, func(ctx context.Context) (Result, error) {
+			atomic.AddInt32(&calls, 1)
+			<-ctx.Done()
+			return "", ctx.Err()
+		})
+		start := time.Now()
+		tc.cancel(g, cancel)
+		g.Close()
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: got %v, want the group to return before the hedge delay", tc.name, elapsed)
+		}
+		if calls := atomic.LoadInt32(&calls); calls != 1 {
+			t.Errorf("%s: got %d calls, want 1", tc.name, calls)
+		}
+		cancel()
+	}
+}
+
+func TestError_Key(t *testing.T) {
+	failing := func(msgs ...string) error {
+		g := New[Result]()
+		for _, msg := range msgs {
+			msg := msg
+			g.Go(func() (Result, error) { return "", errors.New(msg) })
+		}
+		_, err := g.Wait()
+		return err
+	}
+	err1, err2 := failing("executor_1 failed", "executor_2 failed"), failing("executor_2 failed", "executor_1 failed")
+	if err1.(Error).Key() != err2.(Error).Key() {
+		t.Errorf("got different keys for the same errors")
+	}
+	err3 := failing("executor_1 failed", "executor_3 failed")
+	if err1.(Error).Key() == err3.(Error).Key() {
+		t.Errorf("got equal keys for different errors")
+	}
+}
+
+func TestWaitWithFallback(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func() (Result, error)
+		want Result
+	}{
+		{name: "ok response", fn: func() (Result, error) { return "executor_1", nil }, want: "executor_1"},
+		{name: "error", fn: func() (Result, error) { return "", errors.New("executor_1 failed") }, want: "fallback"},
+	}
+	for _, tc := range tests {
+		g := New[Result]()
+		g.Go(tc.fn)
+		if got := g.WaitWithFallback("fallback"); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestOnWin(t *testing.T) {
+	tests := []struct {
+		name      string
+		executors []Executor
+		want      []Result
+	}{
+		{
+			name: "only ok responses",
+			executors: []Executor{
+				{fn: func() (Result, error) { return "executor_1", nil }},
+				{fn: func() (Result, error) { time.Sleep(time.Millisecond * 100); return "executor_2", nil }},
+			},
+			want: []Result{"executor_1"},
+		},
+		{
+			name: "only errors",
+			executors: []Executor{
+				{fn: func() (Result, error) { return "", errors.New("executor_1 failed") }},
+				{fn: func() (Result, error) { return "", errors.New("executor_2 failed") }},
+			},
+		},
+	}
+	for _, tc := range tests {
+		var got []Result
+		g, _ := WithContext[Result](context.Background())
+		g.OnWin(func(ok Result) { got = append(got, ok) })
+		for _, executor := range tc.executors {
+			g.Go(executor.Execute)
+		}
+		g.Wait()
+		if len(got) != len(tc.want) || (len(got) > 0 && got[0] != tc.want[0]) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWaitThenDrain(t *testing.T) {
+	g, _ := WithContext[Result](context.Background())
+	g.Go(func() (Result, error) { return "executor_1", nil })
+	g.Go(func() (Result, error) { time.Sleep(time.Millisecond * 100); return "", errors.New("executor_2 failed") })
+	got, err, drained := g.WaitThenDrain()
+	if err != nil {
+		t.Fatalf("want nil err, got %v", err)
+	}
+	if got != "executor_1" {
+		t.Errorf("got %v, want executor_1", got)
+	}
+	select {
+	case <-drained:
+		t.Errorf("want drain channel open while losers are running")
+	default:
+	}
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		t.Errorf("want drain channel closed")
+	}
+}
+
+func TestWaitThenDrain_OnlyErrors(t *testing.T) {
+	errFailed := errors.New("executor_1 failed")
+	g := New[Result]()
+	g.Go(func() (Result, error) { return "", errFailed })
+	_, err, drained := g.WaitThenDrain()
+	if !errors.Is(err, errFailed) {
+		t.Errorf("got err %v, want err %v", err, errFailed)
+	}
+	select {
+	case <-drained:
+	default:
+		t.Errorf("want drain channel closed")
+	}
+}
+
+func TestWaitMust(t *testing.T) {
+	g := New[Result]()
+	g.Go(func() (Result, error) { return "executor_1", nil })
+	if got := g.WaitMust(); got != "executor_1" {
+		t.Errorf("got %v, want executor_1", got)
+	}
+
+	errFailed := errors.New("executor_1 failed")
+	defer func() {
+		r := recover()
+		err, ok := r.(error)
+		if !ok || !errors.Is(err, errFailed) || err.Error() != errFailed.Error() {
+			t.Errorf("got panic %v, want panic %v", r, errFailed)
+		}
+	}()
+	g = New[Result]()
+	g.Go(func() (Result, error) { return "", errFailed })
+	g.WaitMust()
+}
+
+func TestWaitMust_Name(t *testing.T) {
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || !strings.HasPrefix(err.Error(), "okgroup.Group[okgroup.Result](fetch): ") {
+			t.Errorf("got panic %v, want panic prefixed with the group's name", err)
+		}
+	}()
+	g := New[Result](WithName("fetch"))
+	g.Go(func() (Result, error) { return "", errors.New("executor_1 failed") })
+	g.WaitMust()
+}
+
+func TestSetters_AfterGo(t *testing.T) {
+	setters := map[string]func(g *Group[Result]){
+		"SetKeepPartial": func(g *Group[Result]) { g.SetKeepPartial(true) },
+		"OnWin":          func(g *Group[Result]) { g.OnWin(func(Result) {}) },
+		"OnCancel":       func(g *Group[Result]) { g.OnCancel(func() {}) },
+		"Tap":            func(g *Group[Result]) { g.Tap(func(Result, error) {}) },
+		"OnPanic":        func(g *Group[Result]) { g.OnPanic(func(int, any) error { return nil }) },
+		"SetSpanner": func(g *Group[Result]) {
+			g.SetSpanner(func(ctx context.Context, _ int, _ string) (context.Context, func()) { return ctx, func() {} })
+		},
+	}
+	for name, set := range setters {
+		g := New[Result]()
+		set(g)
+		g.Go(func() (Result, error) { return "executor_1", nil })
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: want panic after Go", name)
+				}
+			}()
+			set(g)
+		}()
+		g.Wait()
+	}
+}
+
+func TestError_Wrap(t *testing.T) {
+	err1, err2 := errors.New("executor_1 failed"), errors.New("executor_2 failed")
+	err := Error{errors: []error{err1, err2}}.Wrap("fetching user")
+	if want := "fetching user: executor_1 failed;executor_2 failed"; err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+	err = err.(Error).Wrap("handling request")
+	if want := "handling request: fetching user: executor_1 failed;executor_2 failed"; err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+	var grouperr Error
+	if !errors.As(err, &grouperr) {
+		t.Fatalf("want Error, got %T", err)
+	}
+	for _, wanterr := range []error{err1, err2} {
+		if !errors.Is(err, wanterr) {
+			t.Errorf("got err %v, want err %v", err, wanterr)
+		}
+	}
+}
+
+func TestWait_CanceledByWin(t *testing.T) {
+	errFailed := errors.New("executor_1 failed")
+	g, ctx := WithContext[Result](context.Background())
+	g.Go(func() (Result, error) { return "", errFailed })
+	g.Go(func() (Result, error) { time.Sleep(time.Millisecond * 50); return "executor_2", nil })
+	g.Go(func() (Result, error) { <-ctx.Done(); return "", ctx.Err() })
+	if _, err := g.Wait(); err != nil {
+		t.Fatalf("want nil err, got %v", err)
+	}
+	_, err := g.WaitAll()
+	if !errors.Is(err, errFailed) {
+		t.Errorf("got err %v, want err %v", err, errFailed)
+	}
+	if errors.Is(err, context.Canceled) {
+		t.Errorf("got err %v, want no context.Canceled", err)
+	}
+}
+
+func TestWait_ParentCanceled(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	g, ctx := WithContext[Result](parent)
+	g.Go(func() (Result, error) { <-ctx.Done(); return "", ctx.Err() })
+	cancel()
+	if _, err := g.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("got err %v, want err %v", err, context.Canceled)
+	}
+}
+
+func TestAsGroupError(t *testing.T) {
+	errFailed := errors.New("executor_1 failed")
+	grouperr := Error{errors: []error{errFailed}}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "group error", err: grouperr, want: true},
+		{name: "wrapped group error", err: fmt.Errorf("fetching user: %w", grouperr), want: true},
+		{name: "plain error", err: errFailed, want: false},
+		{name: "nil error", err: nil, want: false},
+	}
+	for _, tc := range tests {
+		if got := IsGroupError(tc.err); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+		got, ok := AsGroupError(tc.err)
+		if ok != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, ok, tc.want)
+		}
+		if ok && !errors.Is(got, errFailed) {
+			t.Errorf("%s: got err %v, want err %v", tc.name, got, errFailed)
+		}
+	}
+}
+
+func TestClose(t *testing.T) {
+	var exited int32
+	g, ctx := WithContext[Result](context.Background())
+	for i := 0; i < 3; i++ {
+		g.Go(func() (Result, error) {
+			<-ctx.Done()
+			time.Sleep(time.Millisecond * 10)
+			atomic.AddInt32(&exited, 1)
+			return "", ctx.Err()
+		})
+	}
+	g.Close()
+	if got := atomic.LoadInt32(&exited); got != 3 {
+		t.Errorf("got %d exited goroutines, want 3", got)
+	}
+	g.Close()
+}
+
+func TestGoroutineError(t *testing.T) {
+	errFailed := errors.New("executor failed")
+	g := New[Result]()
+	g.Go(func() (Result, error) { time.Sleep(time.Millisecond * 50); return "", errFailed })
+	g.GoNamed("replica", func() (Result, error) { return "", errFailed })
+	_, err := g.Wait()
+	var goerr GoroutineError
+	if !errors.As(err, &goerr) {
+		t.Fatalf("want GoroutineError, got %v", err)
+	}
+	if goerr.Index != 1 || goerr.Name != "replica" || goerr.Err != errFailed {
+		t.Errorf("got %+v, want {Index:1 Name:replica Err:%v}", goerr, errFailed)
+	}
+	grouperr, _ := AsGroupError(err)
+	if goerr := grouperr.errors[1].(GoroutineError); goerr.Index != 0 || goerr.Name != "" {
+		t.Errorf("got %+v, want {Index:0 Name:}", goerr)
+	}
+}
+
+func TestError_BySource(t *testing.T) {
+	err1, err2 := errors.New("executor_1 failed"), errors.New("replica failed")
+	g := New[Result]()
+	g.Go(func() (Result, error) { return "", err1 })
+	g.GoNamed("replica", func() (Result, error) { return "", err2 })
+	_, err := g.Wait()
+	got := err.(Error).BySource()
+	want := map[string]error{"0": err1, "replica": err2}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("got %v for %q, want %v", got[k], k, v)
+		}
+	}
+}
+
+func TestWaitAll_Outcomes(t *testing.T) {
+	g := New[int]()
+	for i := 0; i < 3; i++ {
+		i := i
+		g.Go(func() (int, error) {
+			time.Sleep(time.Duration(i+1) * 20 * time.Millisecond)
+			return i, nil
+		})
+	}
+	oks, err := g.WaitAll()
+	if err != nil {
+		t.Fatalf("want nil err, got %v", err)
+	}
+	if len(oks) != 3 {
+		t.Fatalf("got %v, want 3 outcomes", oks)
+	}
+	for i, ok := range oks {
+		if ok.Index != i || ok.Value != i {
+			t.Errorf("got outcome %+v, want index and value %d", ok, i)
+		}
+		if min := time.Duration(i+1) * 20 * time.Millisecond; ok.Duration < min || ok.Duration > time.Second {
+			t.Errorf("got duration %v, want at least %v", ok.Duration, min)
+		}
+	}
+}
+
+func TestResults(t *testing.T) {
+	if results := New[Result]().Results(); results != nil {
+		t.Errorf("got %v, want nil channel", results)
+	}
+	g := New[Result](WithResults())
+	errFailed := errors.New("executor_2 failed")
+	g.Go(func() (Result, error) { return "executor_1", nil })
+	g.Go(func() (Result, error) { return "", errFailed })
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		g.Wait()
+	}()
+	got := make(map[int]Outcome[Result])
+	for out := range g.Results() {
+		got[out.Index] = out
+	}
+	<-done
+	if out := got[0]; out.Value != "executor_1" || out.Err != nil {
+		t.Errorf("got %+v, want executor_1", out)
+	}
+	if out := got[1]; !errors.Is(out.Err, errFailed) {
+		t.Errorf("got %+v, want err %v", out, errFailed)
+	}
+}
+
+func TestWaitAllOrdered(t *testing.T) {
+	errFailed := errors.New("executor_2 failed")
+	g := New[Result]()
+	g.Go(func() (Result, error) { time.Sleep(time.Millisecond * 100); return "executor_1", nil })
+	g.Go(func() (Result, error) { time.Sleep(time.Millisecond * 50); return "", errFailed })
+	g.Go(func() (Result, error) { return "executor_3", nil })
+	got, err := g.WaitAllOrdered()
+	if !errors.Is(err, errFailed) {
+		t.Errorf("got err %v, want err %v", err, errFailed)
+	}
+	want := []Result{"executor_1", "", "executor_3"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestWaitAllOrdered_Canceled(t *testing.T) {
+	g, ctx := WithContext[Result](context.Background())
+	g.Go(func() (Result, error) { return "executor_1", nil })
+	g.Go(func() (Result, error) { <-ctx.Done(); return "", ctx.Err() })
+	got, err := g.WaitAllOrdered()
+	if want := []Result{"executor_1", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	var goerr GoroutineError
+	if !errors.As(err, &goerr) || goerr.Index != 1 || !errors.Is(err, context.Canceled) {
+		t.Errorf("got err %v, want err of goroutine 1 canceled", err)
+	}
+	if _, err := g.Wait(); err != nil {
+		t.Errorf("got err %v, want nil from Wait", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	g := New[Result]()
+	release := make(chan struct{})
+	g.Go(func() (Result, error) { return "", errors.New("executor_1 failed") })
+	g.Go(func() (Result, error) { <-release; return "", errors.New("executor_2 failed") })
+	for len(g.Errors()) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if got := len(g.Errors()); got != 1 {
+		t.Errorf("got %d errors, want 1", got)
+	}
+	close(release)
+	for len(g.Errors()) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	g.Go(func() (Result, error) { return "", errors.New("executor_3 failed") })
+	g.Wait()
+	if got := len(g.Errors()); got != 3 {
+		t.Errorf("got %d errors, want 3", got)
+	}
+}
+
+func TestCancelAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		delays  []time.Duration
+		want    Result
+		wanterr error
+	}{
+		{name: "canceled", delays: []time.Duration{time.Millisecond * 10}, wanterr: context.Canceled},
+		{name: "timer replaced", delays: []time.Duration{time.Millisecond * 10, time.Hour}, want: "executor_1"},
+	}
+	for _, tc := range tests {
+		g, ctx := WithContext[Result](context.Background())
+		for _, d := range tc.delays {
+			g.CancelAfter(d)
+		}
+		g.Go(func() (Result, error) {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(time.Millisecond * 50):
+				return "executor_1", nil
+			}
+		})
+		got, err := g.Wait()
+		if tc.wanterr == nil && err != nil {
+			t.Errorf("%s: want nil err, got %v", tc.name, err)
+		}
+		if !errors.Is(err, tc.wanterr) {
+			t.Errorf("%s: got err %v, want err %v", tc.name, err, tc.wanterr)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+		g.CancelAfter(time.Millisecond)
+	}
+}
+
+func TestOnCancel(t *testing.T) {
+	tests := []struct {
+		name      string
+		executors []Executor
+	}{
+		{
+			name: "winning race",
+			executors: []Executor{
+				{fn: func() (Result, error) { return "executor_1", nil }},
+				{fn: func() (Result, error) { time.Sleep(time.Millisecond * 50); return "executor_2", nil }},
+			},
+		},
+		{
+			name: "all failures",
+			executors: []Executor{
+				{fn: func() (Result, error) { return "", errors.New("executor_1 failed") }},
+				{fn: func() (Result, error) { return "", errors.New("executor_2 failed") }},
+			},
+		},
+	}
+	for _, tc := range tests {
+		for _, g := range []*Group[Result]{New[Result](), func() *Group[Result] { g, _ := WithContext[Result](context.Background()); return g }()} {
+			var calls int32
+			g.OnCancel(func() { atomic.AddInt32(&calls, 1) })
+			for _, executor := range tc.executors {
+				g.Go(executor.Execute)
+			}
+			g.Wait()
+			g.Close()
+			if got := atomic.LoadInt32(&calls); got != 1 {
+				t.Errorf("%s: got %d calls, want 1", tc.name, got)
+			}
+		}
+	}
+}
+
+func TestCancelAfterN(t *testing.T) {
+	g, ctx := WithContext[Result](context.Background())
+	g.CancelAfterN(2)
+	g.Go(func() (Result, error) { return "", errors.New("executor_1 failed") })
+	g.Go(func() (Result, error) { return "", errors.New("executor_2 failed") })
+	for i := 0; i < 3; i++ {
+		g.Go(func() (Result, error) {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(time.Second):
+				return "executor", nil
+			}
+		})
+	}
+	start := time.Now()
+	_, err := g.Wait()
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got err %v, want err %v", err, context.Canceled)
+	}
+	if d := time.Since(start); d > time.Millisecond*500 {
+		t.Errorf("got Wait returned after %v, want canceled goroutines", d)
+	}
+}
+
+func TestGo_ConcurrentOkResponses(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		var wins int32
+		g, _ := WithContext[int](context.Background())
+		g.OnWin(func(int) { atomic.AddInt32(&wins, 1) })
+		for j := 0; j < 100; j++ {
+			j := j
+			g.Go(func() (int, error) { return j, nil })
+		}
+		got, err := g.Wait()
+		if err != nil {
+			t.Fatalf("want nil err, got %v", err)
+		}
+		oks, _ := g.WaitAll()
+		if len(oks) != 100 || oks[0].Value != got {
+			t.Errorf("got %v, want the first of 100 ok responses", got)
+		}
+		if wins := atomic.LoadInt32(&wins); wins != 1 {
+			t.Errorf("got %d wins, want 1", wins)
+		}
+	}
+}
+
+func BenchmarkGo_ConcurrentOkResponses(b *testing.B) {
+	fn := func() (Result, error) { return "executor", nil }
+	for i := 0; i < b.N; i++ {
+		g, _ := WithContext[Result](context.Background())
+		for j := 0; j < 100; j++ {
+			g.Go(fn)
+		}
+		g.Wait()
+	}
+}
+
+func TestString(t *testing.T) {
+	g, _ := WithContext[Result](context.Background())
+	release := make(chan struct{})
+	g.Go(func() (Result, error) { return "", errors.New("executor_1 failed") })
+	g.Go(func() (Result, error) { <-release; return "executor_2", nil })
+	for !strings.Contains(g.String(), "completed: 1") {
+		time.Sleep(time.Millisecond)
+	}
+	for _, want := range []string{"submitted: 2", "completed: 1", "won: false", "canceled: false"} {
+		if got := fmt.Sprintf("%v", g); !strings.Contains(got, want) {
+			t.Errorf("got %q, want it to contain %q", got, want)
+		}
+	}
+	close(release)
+	g.Wait()
+	for _, want := range []string{"completed: 2", "won: true", "canceled: true"} {
+		if got := g.String(); !strings.Contains(got, want) {
+			t.Errorf("got %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestString_Name(t *testing.T) {
+	tests := []struct {
+		g    *Group[string]
+		want string
+	}{
+		{New[string](), "okgroup.Group[string]{"},
+		{New[string](WithName("fetch")), "okgroup.Group[string](fetch){"},
+	}
+	for _, tc := range tests {
+		if got := tc.g.String(); !strings.HasPrefix(got, tc.want) {
+			t.Errorf("got %q, want prefix %q", got, tc.want)
+		}
+	}
+}
+
+func TestTap(t *testing.T) {
+	var oks, errs int32
+	g := New[Result]()
+	g.Tap(func(ok Result, err error) {
+		if err != nil {
+			atomic.AddInt32(&errs, 1)
+			return
+		}
+		atomic.AddInt32(&oks, 1)
+	})
+	g.Tap(func(Result, error) { panic("tap") })
+	g.Go(func() (Result, error) { return "executor_1", nil })
+	g.Go(func() (Result, error) { return "", errors.New("executor_2 failed") })
+	g.Go(func() (Result, error) { return "", errors.New("executor_3 failed") })
+	got, err := g.Wait()
+	if err != nil {
+		t.Fatalf("want nil err, got %v", err)
+	}
+	if got != "executor_1" {
+		t.Errorf("got %v, want executor_1", got)
+	}
+	if oks, errs := atomic.LoadInt32(&oks), atomic.LoadInt32(&errs); oks != 1 || errs != 2 {
+		t.Errorf("got %d ok responses and %d errors, want 1 and 2", oks, errs)
+	}
+}
+
+func TestWait_Deadline(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	g, _ := WithContext[Result](parent)
+	g.Go(func() (Result, error) { return "", errors.New("executor_1 failed") })
+	g.Go(func() (Result, error) { time.Sleep(time.Millisecond * 500); return "executor_2", nil })
+	start := time.Now()
+	_, err := g.Wait()
+	if d := time.Since(start); d > time.Millisecond*250 {
+		t.Errorf("got Wait returned after %v, want near the deadline", d)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got err %v, want err %v", err, context.DeadlineExceeded)
+	}
+	g.Close()
+	if oks, _ := g.WaitAll(); len(oks) != 1 {
+		t.Errorf("got %v, want the straggler's ok response after Close", oks)
+	}
+}
+
+func TestWaitMeta(t *testing.T) {
+	errFailed := errors.New("executor_1 failed")
+	g := New[Result]()
+	g.GoWithMeta("request-1", func() (Result, error) { return "", errFailed })
+	g.GoWithMeta("request-2", func() (Result, error) { time.Sleep(time.Millisecond * 10); return "executor_2", nil })
+	got, err := g.WaitMeta()
+	if err != nil {
+		t.Fatalf("want nil err, got %v", err)
+	}
+	if got.Value != "executor_2" || got.Meta != "request-2" {
+		t.Errorf("got %+v, want {Value:executor_2 Meta:request-2}", got)
+	}
+	_, err = g.WaitAll()
+	var goerr GoroutineError
+	if !errors.As(err, &goerr) || goerr.Meta != "request-1" {
+		t.Errorf("got %+v, want GoroutineError with Meta request-1", goerr)
+	}
+}
+
+func TestGoBatch(t *testing.T) {
+	errFailed := errors.New("executor failed")
+	fns := make([]func() (int, error), 100)
+	for i := range fns {
+		i := i
+		fns[i] = func() (int, error) {
+			if i%2 == 0 {
+				return 0, errFailed
+			}
+			return i, nil
+		}
+	}
+	batch, loop := New[int](), New[int]()
+	batch.GoBatch(fns)
+	for _, fn := range fns {
+		loop.Go(fn)
+	}
+	got, goterr := batch.WaitAllOrdered()
+	want, wanterr := loop.WaitAllOrdered()
+	if len(got) != len(want) {
+		t.Fatalf("got %d results, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v at %d, want %v", got[i], i, want[i])
+		}
+	}
+	if len(goterr.(Error).errors) != len(wanterr.(Error).errors) {
+		t.Errorf("got err %v, want err %v", goterr, wanterr)
+	}
+}
+
+func BenchmarkGoBatch(b *testing.B) {
+	fns := make([]func() (Result, error), 100000)
+	for i := range fns {
+		fns[i] = func() (Result, error) { return "executor", nil }
+	}
+	b.Run("Go", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g := New[Result]()
+			for _, fn := range fns {
+				g.Go(fn)
+			}
+			g.Wait()
+		}
+	})
+	b.Run("GoBatch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g := New[Result]()
+			g.GoBatch(fns)
+			g.Wait()
+		}
+	})
+}
+
+func TestFirstError(t *testing.T) {
+	g := New[Result]()
+	if err := g.FirstError(); err != nil {
+		t.Errorf("got err %v, want nil", err)
+	}
+	slow := errors.New("executor_1 failed")
+	fast := errors.New("executor_2 failed")
+	g.Go(func() (Result, error) { time.Sleep(50 * time.Millisecond); return "", slow })
+	g.Go(func() (Result, error) { return "", fast })
+	for g.FirstError() == nil {
+		time.Sleep(time.Millisecond)
+	}
+	if err := g.FirstError(); !errors.Is(err, fast) {
+		t.Errorf("got err %v, want err %v", err, fast)
+	}
+	g.Wait()
+	if err := g.FirstError(); !errors.Is(err, fast) {
+		t.Errorf("got err %v, want err %v", err, fast)
+	}
+	var goerr GoroutineError
+	if !errors.As(g.FirstError(), &goerr) || goerr.Index != 1 {
+		t.Errorf("got %v, want error of goroutine 1", g.FirstError())
+	}
+}
+
+type fakeSpanner struct {
+	mu     sync.Mutex
+	starts map[int]int
+	ends   map[int]int
+}
+
+type spanKey struct{}
+
+func (s *fakeSpanner) span(ctx context.Context, index int, _ string) (context.Context, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.starts[index]++
+	return context.WithValue(ctx, spanKey{}, index), func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.ends[index]++
+	}
+}
+
+func TestSetSpanner(t *testing.T) {
+	spanner := &fakeSpanner{starts: make(map[int]int), ends: make(map[int]int)}
+	g := New[Result]()
+	g.SetSpanner(spanner.span)
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	for i := 0; i < 3; i++ {
+		g.GoCtx(func(ctx context.Context) (Result, error) {
+			index, ok := ctx.Value(spanKey{}).(int)
+			if !ok {
+				return "", errors.New("no span in context")
+			}
+			mu.Lock()
+			seen[index] = true
+			mu.Unlock()
+			return "", fmt.Errorf("executor_%d failed", index+1)
+		})
+	}
+	g.Wait()
+	for i := 0; i < 3; i++ {
+		if !seen[i] {
+			t.Errorf("goroutine %d: want span in context", i)
+		}
+		if spanner.starts[i] != 1 || spanner.ends[i] != 1 {
+			t.Errorf("goroutine %d: got %d starts and %d ends, want 1 and 1", i, spanner.starts[i], spanner.ends[i])
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	g := New[Result]()
+	release := make(chan struct{})
+	g.Go(func() (Result, error) { return "", errors.New("executor_1 failed") })
+	g.Go(func() (Result, error) { <-release; return "executor_2", nil })
+	for g.Snapshot().Failed < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	got := g.Snapshot()
+	if got.Submitted != 2 || got.Running != 1 || got.Succeeded != 0 || got.Failed != 1 || got.HasResult {
+		t.Errorf("got %+v, want 2 submitted, 1 running and 1 failed", got)
+	}
+	if len(got.PendingErrors) != 1 {
+		t.Errorf("got %v, want 1 pending error", got.PendingErrors)
+	}
+	close(release)
+	g.Wait()
+	got = g.Snapshot()
+	if got.Submitted != 2 || got.Running != 0 || got.Succeeded != 1 || got.Failed != 1 || !got.HasResult {
+		t.Errorf("got %+v, want 2 submitted, 1 succeeded and 1 failed", got)
+	}
+}
+
+func TestError_Format(t *testing.T) {
+	err := Error{msg: "okgroup", errors: []error{errors.New("executor_1 failed"), errors.New("executor_2 failed")}}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%v", "okgroup: executor_1 failed;executor_2 failed"},
+		{"%s", "okgroup: executor_1 failed;executor_2 failed"},
+		{"%q", `"okgroup: executor_1 failed;executor_2 failed"`},
+		{"%d", "2"},
+		{"%+v", "okgroup:\n    [0] executor_1 failed\n    [1] executor_2 failed"},
+	}
+	for _, tc := range tests {
+		if got := fmt.Sprintf(tc.format, err); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.format, got, tc.want)
+		}
+	}
+}
+
+func TestWaitChan(t *testing.T) {
+	g := New[Result]()
+	release := make(chan struct{})
+	g.Go(func() (Result, error) { <-release; return "executor_1", nil })
+	timer := time.NewTimer(10 * time.Millisecond)
+	select {
+	case out := <-g.WaitChan():
+		t.Fatalf("got %v, want timer to fire first", out)
+	case <-timer.C:
+	}
+	close(release)
+	timer = time.NewTimer(time.Second)
+	defer timer.Stop()
+	select {
+	case out := <-g.WaitChan():
+		if out.Value != "executor_1" || out.Err != nil {
+			t.Errorf("got %v, want executor_1", out)
+		}
+	case <-timer.C:
+		t.Fatal("want outcome before timer")
+	}
+	if _, ok := <-g.WaitChan(); ok {
+		t.Error("want closed channel")
+	}
+}
+
+func TestWaitStream(t *testing.T) {
+	errFailed := errors.New("executor_3 failed")
+	g, _ := WithContext[Result](context.Background(), WithNoCancelOnSuccess())
+	g.Go(func() (Result, error) { return "executor_1", nil })
+	g.Go(func() (Result, error) { <-g.winCh; return "executor_2", nil })
+	g.Go(func() (Result, error) { <-g.winCh; return "", errFailed })
+	<-g.winCh
+	got := map[Result]bool{}
+	for ok := range g.WaitStream() {
+		got[ok] = true
+	}
+	if len(got) != 2 || !got["executor_1"] || !got["executor_2"] {
+		t.Errorf("got %v, want executor_1 and executor_2", got)
+	}
+	if err := g.WaitStreamErr(); !errors.Is(err, errFailed) {
+		t.Errorf("got %v, want %v", err, errFailed)
+	}
+	if ch := g.WaitStream(); ch != g.WaitStream() {
+		t.Error("want the same channel")
+	}
+}
+
+func TestWaitStream_Failure(t *testing.T) {
+	g := New[Result]()
+	g.Go(func() (Result, error) { return "", errors.New("executor_1 failed") })
+	for ok := range g.WaitStream() {
+		t.Errorf("got %v, want no ok responses", ok)
+	}
+	if err := g.WaitStreamErr(); err == nil {
+		t.Error("got nil error, want error")
+	}
+}
+
+func TestSeq2_ErrorBuffer(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		collect func(g *Group[Result]) []error
+	}{
+		{name: "Seq2", collect: func(g *Group[Result]) []error {
+			var errs []error
+			for _, err := range g.Seq2() {
+				errs = append(errs, err)
+			}
+			return errs
+		}},
+		{name: "Results", opts: []Option{WithResults()}, collect: func(g *Group[Result]) []error {
+			go g.Wait()
+			var errs []error
+			for outcome := range g.Results() {
+				errs = append(errs, outcome.Err)
+			}
+			return errs
+		}},
+	}
+	for _, tc := range tests {
+		g := New[Result](append(tc.opts, WithErrorBuffer(1))...)
+		for i := 0; i < 3; i++ {
+			g.Go(func() (Result, error) { return "", errors.New("executor failed") })
+		}
+		errs := tc.collect(g)
+		g.Wait()
+		if got, want := len(errs), len(g.Errors()); got != 1 || got != want {
+			t.Errorf("%s: got %d errors, want %d retained by the group", tc.name, got, want)
+		}
+	}
+}
+
+func TestError_Slice(t *testing.T) {
+	err1, err2 := errors.New("executor_1 failed"), errors.New("executor_2 failed")
+	err := Error{errors: []error{err1, err2}}
+	for _, got := range [][]error{err.Errors(), err.Slice()} {
+		if len(got) != 2 || got[0] != err1 || got[1] != err2 {
+			t.Errorf("got %v, want [%v %v]", got, err1, err2)
+		}
+	}
+	err.Slice()[0] = nil
+	if err.errors[0] != err1 {
+		t.Error("want Slice to return a copy")
+	}
+}
+
+func TestReset(t *testing.T) {
+	g, ctx := WithContext[Result](context.Background())
+	g.Go(func() (Result, error) { return "", errors.New("executor_1 failed") })
+	g.Go(func() (Result, error) { return "executor_2", nil })
+	g.Wait()
+	if ctx.Err() == nil {
+		t.Fatal("want canceled context")
+	}
+	ctx = g.Reset(context.Background())
+	if ctx.Err() != nil {
+		t.Errorf("got context err %v, want nil", ctx.Err())
+	}
+	if got := g.Snapshot(); got.Submitted != 0 || got.Failed != 0 || got.HasResult || g.FirstError() != nil {
+		t.Errorf("got %+v, want no residual state", got)
+	}
+	g.Go(func() (Result, error) { return "", errors.New("executor_3 failed") })
+	if _, err := g.Wait(); err == nil || !strings.Contains(err.Error(), "executor_3") || strings.Contains(err.Error(), "executor_1") {
+		t.Errorf("got err %v, want executor_3 error only", err)
+	}
+	if ctx.Err() == nil {
+		t.Error("want canceled context")
+	}
+}
+
+func TestReset_Pool(t *testing.T) {
+	pool := sync.Pool{New: func() any { return New[int](WithQuorum(2)) }}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := 0; batch < 50; batch++ {
+				g := pool.Get().(*Group[int])
+				g.Reset(context.Background())
+				for j := 0; j < 3; j++ {
+					j := j
+					g.Go(func() (int, error) { return batch*10 + j, nil })
+				}
+				got, err := g.Wait()
+				if err != nil || got/10 != batch {
+					t.Errorf("batch %d: got %d, %v, want ok response of the batch", batch, got, err)
+				}
+				pool.Put(g)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestOnPanic(t *testing.T) {
+	errPanicked := errors.New("executor_1 panicked")
+	tests := []struct {
+		name    string
+		onPanic func(index int, recovered any) error
+		want    func(err error) bool
+	}{
+		{
+			name: "default",
+			want: func(err error) bool {
+				var panicerr PanicError
+				return errors.As(err, &panicerr) && panicerr.Value == "boom" && len(panicerr.Stack) > 0
+			},
+		},
+		{
+			name:    "convert to error",
+			onPanic: func(index int, recovered any) error { return errPanicked },
+			want:    func(err error) bool { return errors.Is(err, errPanicked) },
+		},
+		{
+			name:    "swallow",
+			onPanic: func(index int, recovered any) error { return nil },
+			want:    func(err error) bool { return !strings.Contains(err.Error(), "panic") },
+		},
+	}
+	for _, tc := range tests {
+		g := New[Result]()
+		if tc.onPanic != nil {
+			g.OnPanic(tc.onPanic)
+		}
+		g.Go(func() (Result, error) { panic("boom") })
+		g.Go(func() (Result, error) { return "", errors.New("executor_2 failed") })
+		_, err := g.Wait()
+		if err == nil || !tc.want(err) {
+			t.Errorf("%s: got err %v", tc.name, err)
+		}
+		if tc.name == "swallow" && len(g.Errors()) != 1 {
+			t.Errorf("%s: got errors %v, want 1 error", tc.name, g.Errors())
+		}
+	}
+}
+
+func TestWait_NoGoroutines(t *testing.T) {
+	g := New[Result]()
+	if _, err := g.Wait(); err != ErrNoGoroutines {
+		t.Errorf("got err %v, want err %v", err, ErrNoGoroutines)
+	}
+	g = New[Result](WithDefault[Result]("fallback"))
+	if got, err := g.Wait(); got != "fallback" || err != nil {
+		t.Errorf("got %v, %v, want fallback, nil", got, err)
+	}
+}
+
+func TestError_Empty(t *testing.T) {
+	tests := []struct {
+		err  Error
+		want string
+	}{
+		{Error{}, ""},
+		{Error{msg: "fetch"}, "fetch"},
+	}
+	for _, tc := range tests {
+		if got := tc.err.Error(); got != tc.want {
+			t.Errorf("got %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestWait_GroupCanceled(t *testing.T) {
+	tests := []struct {
+		fns  []func(ctx context.Context) (Result, error)
+		want bool
+	}{
+		{
+			fns: []func(ctx context.Context) (Result, error){
+				func(ctx context.Context) (Result, error) { <-ctx.Done(); return "", ctx.Err() },
+				func(ctx context.Context) (Result, error) {
+					<-ctx.Done()
+					return "", fmt.Errorf("executor_2: %w", ctx.Err())
+				},
+			},
+			want: true,
+		},
+		{
+			fns: []func(ctx context.Context) (Result, error){
+				func(ctx context.Context) (Result, error) { <-ctx.Done(); return "", ctx.Err() },
+				func(ctx context.Context) (Result, error) { return "", errors.New("executor_2 failed") },
+			},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		parent, cancel := context.WithCancel(context.Background())
+		g, _ := WithContext[Result](parent)
+		for _, f := range tc.fns {
+			g.GoCtx(f)
+		}
+		cancel()
+		_, err := g.Wait()
+		if got := errors.Is(err, ErrGroupCanceled); got != tc.want {
+			t.Errorf("got errors.Is(%v, ErrGroupCanceled) %t, want %t", err, got, tc.want)
+		}
+		if !IsGroupError(err) {
+			t.Errorf("got err %v, want Error", err)
+		}
+	}
+}
+
+func TestWaitWithTimeout(t *testing.T) {
+	slow := func() (Result, error) { time.Sleep(100 * time.Millisecond); return "slow", nil }
+	tests := []struct {
+		fns     []func() (Result, error)
+		want    Result
+		timeout bool
+	}{
+		{
+			fns:  []func() (Result, error){func() (Result, error) { return "executor_1", nil }},
+			want: "executor_1",
+		},
+		{
+			fns:     []func() (Result, error){slow},
+			timeout: true,
+		},
+		{
+			fns:  []func() (Result, error){slow, func() (Result, error) { return "executor_2", nil }},
+			want: "executor_2",
+		},
+	}
+	for _, tc := range tests {
+		g := New[Result]()
+		for _, f := range tc.fns {
+			g.Go(f)
+		}
+		got, err := g.WaitWithTimeout(20 * time.Millisecond)
+		if got != tc.want {
+			t.Errorf("got %v, want %v", got, tc.want)
+		}
+		if timeout := errors.Is(err, ErrGroupTimeout); timeout != tc.timeout {
+			t.Errorf("got err %v, want timeout %t", err, tc.timeout)
+		}
+		g.Close()
+	}
+}
+
+type timeoutError bool
+
+func (e timeoutError) Error() string { return "timeout error" }
+
+func (e timeoutError) Timeout() bool { return bool(e) }
+
+func TestError_Timeout(t *testing.T) {
+	errFailed := errors.New("executor_1 failed")
+	tests := []struct {
+		errs []error
+		want bool
+	}{
+		{[]error{errFailed}, false},
+		{[]error{errFailed, fmt.Errorf("executor_2: %w", context.DeadlineExceeded)}, true},
+		{[]error{errFailed, GoroutineError{Err: timeoutError(true)}}, true},
+		{[]error{timeoutError(false)}, false},
+	}
+	for _, tc := range tests {
+		if got := (Error{errors: tc.errs}).Timeout(); got != tc.want {
+			t.Errorf("got Timeout() %t for %v, want %t", got, tc.errs, tc.want)
+		}
+	}
+}
+
+type temporaryError bool
+
+func (e temporaryError) Error() string { return "temporary error" }
+
+func (e temporaryError) Temporary() bool { return bool(e) }
+
+func TestError_Temporary(t *testing.T) {
+	errFailed := errors.New("executor_1 failed")
+	tests := []struct {
+		errs []error
+		want bool
+	}{
+		{nil, false},
+		{[]error{temporaryError(true), context.Canceled, GoroutineError{Err: context.DeadlineExceeded}}, true},
+		{[]error{temporaryError(true), errFailed}, false},
+		{[]error{temporaryError(false)}, false},
+	}
+	for _, tc := range tests {
+		if got := (Error{errors: tc.errs}).Temporary(); got != tc.want {
+			t.Errorf("got Temporary() %t for %v, want %t", got, tc.errs, tc.want)
+		}
+	}
+}
+
+func TestGoCtx_Handle(t *testing.T) {
+	g, _ := WithContext[Result](context.Background(), WithNoCancelOnSuccess())
+	release := make(chan struct{})
+	var mu sync.Mutex
+	canceled := make(map[int]bool)
+	var handles []Handle
+	for i := 0; i < 3; i++ {
+		i := i
+		handles = append(handles, g.GoCtx(func(ctx context.Context) (Result, error) {
+			select {
+			case <-ctx.Done():
+				mu.Lock()
+				canceled[i] = true
+				mu.Unlock()
+				return "", ctx.Err()
+			case <-release:
+				return Result(fmt.Sprintf("executor_%d", i+1)), nil
+			}
+		}))
+	}
+	handles[1].Cancel()
+	for len(g.Errors()) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	oks, err := g.WaitAll()
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got err %v, want err %v", err, context.Canceled)
+	}
+	if len(oks) != 2 {
+		t.Errorf("got %v, want 2 ok responses", oks)
+	}
+	if !canceled[1] || canceled[0] || canceled[2] {
+		t.Errorf("got canceled %v, want only executor_2 canceled", canceled)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	g, ctx := WithTimeout[Result](context.Background(), 20*time.Millisecond)
+	for i := 0; i < 2; i++ {
+		g.Go(func() (Result, error) { <-ctx.Done(); return "", ctx.Err() })
+	}
+	start := time.Now()
+	_, err := g.Wait()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("got Wait returning after %v, want return after the timeout", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got err %v, want err %v", err, context.DeadlineExceeded)
+	}
+
+	g2, ctx2 := WithTimeout[Result](context.Background(), time.Second)
+	g2.Go(func() (Result, error) { return "executor_1", nil })
+	g2.Go(func() (Result, error) { <-ctx2.Done(); return "", ctx2.Err() })
+	if got, err := g2.Wait(); got != "executor_1" || err != nil {
+		t.Errorf("got %v, %v, want executor_1, nil", got, err)
+	}
+	if !errors.Is(ctx2.Err(), context.Canceled) {
+		t.Errorf("got ctx err %v, want ctx canceled by the win", ctx2.Err())
+	}
+}
+
+func TestWithStop(t *testing.T) {
+	stop := make(chan struct{})
+	g, ctx := WithStop[Result](stop)
+	started := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		g.Go(func() (Result, error) { started <- struct{}{}; <-ctx.Done(); return "", ctx.Err() })
+	}
+	<-started
+	<-started
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("got ctx err %v, want nil before stop is closed", err)
+	}
+	close(stop)
+	_, err := g.Wait()
+	if !errors.Is(err, ErrGroupCanceled) {
+		t.Errorf("got err %v, want err %v", err, ErrGroupCanceled)
+	}
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Errorf("got ctx err %v, want %v", ctx.Err(), context.Canceled)
+	}
+
+	g2, _ := WithStop[Result](make(chan struct{}))
+	g2.Go(func() (Result, error) { return "executor_1", nil })
+	if got, err := g2.Wait(); got != "executor_1" || err != nil {
+		t.Errorf("got %v, %v, want executor_1, nil", got, err)
+	}
+}
+
+func TestWithStop_Leak(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		g, _ := WithStop[Result](stop)
+		g.Go(func() (Result, error) { return "executor_1", nil })
+		g.Wait()
+		Clone(g).Close()
+	}
+	// The watching goroutines exit asynchronously once the groups' contexts are done.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := runtime.NumGoroutine(); got > before {
+		t.Errorf("got %d goroutines, want at most %d", got, before)
+	}
+}
+
+func TestSubmitted(t *testing.T) {
+	g := New[Result]()
+	if got := g.Submitted(); got != 0 {
+		t.Errorf("got %d submitted, want 0", got)
+	}
+	release := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		g.Go(func() (Result, error) { <-release; return "executor", nil })
+	}
+	if got := g.Submitted(); got != 3 {
+		t.Errorf("got %d submitted, want 3", got)
+	}
+	close(release)
+	g.Wait()
+	if got := g.Submitted(); got != 3 {
+		t.Errorf("got %d submitted, want 3", got)
+	}
+}
+
+func TestSucceededFailed(t *testing.T) {
+	g, ctx := WithContext[Result](context.Background())
+	release := make(chan struct{})
+	g.Go(func() (Result, error) { return "", errors.New("executor_1 failed") })
+	g.Go(func() (Result, error) { <-release; return "executor_2", nil })
+	g.Go(func() (Result, error) { <-ctx.Done(); return "", ctx.Err() })
+	for g.Failed() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if got := g.Succeeded(); got != 0 {
+		t.Errorf("got %d succeeded, want 0", got)
+	}
+	close(release)
+	g.Wait()
+	if succeeded, failed := g.Succeeded(), g.Failed(); succeeded != 1 || failed != 2 {
+		t.Errorf("got %d succeeded and %d failed, want 1 and 2", succeeded, failed)
+	}
+	if got := g.Succeeded() + g.Failed(); got != g.Submitted() {
+		t.Errorf("got %d succeeded and failed, want %d submitted", got, g.Submitted())
+	}
+}
+
+func TestTryResult(t *testing.T) {
+	g := New[Result]()
+	release := make(chan struct{})
+	g.Go(func() (Result, error) { return "", errors.New("executor_1 failed") })
+	g.Go(func() (Result, error) { <-release; return "executor_2", nil })
+	if got, ok := g.TryResult(); ok || got != "" {
+		t.Errorf("got %v, %t, want no ok response", got, ok)
+	}
+	close(release)
+	<-g.winCh
+	g.Go(func() (Result, error) { return "executor_3", nil })
+	if got, ok := g.TryResult(); !ok || got != "executor_2" {
+		t.Errorf("got %v, %t, want executor_2, true", got, ok)
+	}
+	g.Wait()
+}
+
+func TestFuture(t *testing.T) {
+	tests := []struct {
+		fns     []func() (Result, error)
+		want    Result
+		wantErr bool
+	}{
+		{
+			fns: []func() (Result, error){
+				func() (Result, error) { return "", errors.New("executor_1 failed") },
+				func() (Result, error) { time.Sleep(10 * time.Millisecond); return "executor_2", nil },
+			},
+			want: "executor_2",
+		},
+		{
+			fns: []func() (Result, error){
+				func() (Result, error) { return "", errors.New("executor_1 failed") },
+				func() (Result, error) { return "", errors.New("executor_2 failed") },
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		g := New[Result]()
+		type result struct {
+			ok  Result
+			err error
+		}
+		got := make(chan result)
+		future := g.Future()
+		go func() {
+			ok, err := future.Get()
+			got <- result{ok, err}
+		}()
+		go func() {
+			for _, f := range tc.fns {
+				g.Go(f)
+			}
+			g.Wait()
+		}()
+		res := <-got
+		if res.ok != tc.want || (res.err != nil) != tc.wantErr {
+			t.Errorf("got %v, %v, want %v and error %t", res.ok, res.err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestFuture_Frozen(t *testing.T) {
+	errFailed := errors.New("executor_1 failed")
+	g := New[Result]()
+	future := g.Future()
+	got := make(chan error)
+	go func() {
+		_, err := future.Get()
+		got <- err
+	}()
+	release := make(chan struct{})
+	g.Go(func() (Result, error) { <-release; return "", errFailed })
+	g.Freeze()
+	close(release)
+	select {
+	case err := <-got:
+		if !errors.Is(err, errFailed) {
+			t.Errorf("got %v, want %v", err, errFailed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("want Get to return without Wait once the frozen group fails")
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	g := New[Result]()
+	release := make(chan struct{})
+	g.Go(func() (Result, error) { <-release; return "executor_1", nil })
+	if g.IsFrozen() {
+		t.Error("want group not frozen")
+	}
+	g.Freeze()
+	if !g.IsFrozen() {
+		t.Error("want group frozen")
+	}
+	if err := g.TryGo(func() (Result, error) { return "executor_2", nil }); err != ErrGroupFrozen {
+		t.Errorf("got err %v, want err %v", err, ErrGroupFrozen)
+	}
+	submits := map[string]func(){
+		"Go":      func() { g.Go(func() (Result, error) { return "executor_2", nil }) },
+		"GoBatch": func() { g.GoBatch([]func() (Result, error){func() (Result, error) { return "executor_2", nil }}) },
+		"GoKeyed": func() { g.GoKeyed("executor_2", func() (Result, error) { return "executor_2", nil }) },
+	}
+	for name, submit := range submits {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: want panic after Freeze", name)
+				}
+			}()
+			submit()
+		}()
+	}
+	close(release)
+	if got, err := g.Wait(); got != "executor_1" || err != nil {
+		t.Errorf("got %v, %v, want executor_1, nil", got, err)
+	}
+	if got := g.Submitted(); got != 1 {
+		t.Errorf("got %d submitted, want 1", got)
+	}
+}
+
+func TestWithContext_Cause(t *testing.T) {
+	errParent := errors.New("parent canceled")
+	tests := []struct {
+		name string
+		run  func() context.Context
+		want error
+	}{
+		{
+			name: "won",
+			run: func() context.Context {
+				g, ctx := WithContext[Result](context.Background())
+				g.Go(func() (Result, error) { return "executor_1", nil })
+				g.Go(func() (Result, error) { <-ctx.Done(); return "", ctx.Err() })
+				g.Wait()
+				return ctx
+			},
+			want: ErrWon,
+		},
+		{
+			name: "timeout",
+			run: func() context.Context {
+				g, ctx := WithTimeout[Result](context.Background(), 10*time.Millisecond)
+				g.Go(func() (Result, error) { <-ctx.Done(); return "", ctx.Err() })
+				g.Wait()
+				return ctx
+			},
+			want: ErrGroupTimeout,
+		},
+		{
+			name: "parent",
+			run: func() context.Context {
+				parent, cancel := context.WithCancelCause(context.Background())
+				g, ctx := WithContext[Result](parent)
+				g.Go(func() (Result, error) { <-ctx.Done(); return "", ctx.Err() })
+				cancel(errParent)
+				g.Wait()
+				return ctx
+			},
+			want: errParent,
+		},
+	}
+	for _, tc := range tests {
+		ctx := tc.run()
+		if got := context.Cause(ctx); got != tc.want {
+			t.Errorf("%s: got cause %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	var wins int32
+	template, _ := WithContext[Result](context.Background(), WithQuorum(2))
+	template.OnWin(func(Result) { atomic.AddInt32(&wins, 1) })
+	release := make(chan struct{})
+	template.Go(func() (Result, error) { <-release; return "executor_1", nil })
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("want panic with running goroutines")
+			}
+		}()
+		Clone(template)
+	}()
+	close(release)
+	template.Close()
+
+	g := Clone(template)
+	if got := g.Submitted(); got != 0 {
+		t.Errorf("got %d submitted, want 0", got)
+	}
+	if err := g.Context().Err(); err != nil {
+		t.Errorf("got ctx err %v, want nil", err)
+	}
+	g.Go(func() (Result, error) { return "executor_1", nil })
+	g.Go(func() (Result, error) { return "executor_2", nil })
+	if _, err := g.Wait(); err != nil {
+		t.Errorf("want nil err, got %v", err)
+	}
+	if got := atomic.LoadInt32(&wins); got != 1 {
+		t.Errorf("got %d wins, want 1", got)
+	}
+	g = Clone(template)
+	g.Go(func() (Result, error) { return "executor_1", nil })
+	if _, err := g.Wait(); !errors.Is(err, ErrNoQuorum) {
+		t.Errorf("got err %v, want err %v", err, ErrNoQuorum)
+	}
+}
+
+func TestDiscarded(t *testing.T) {
+	tests := []struct {
+		opts []Option
+		late func(ctx context.Context) (Result, error)
+		want int
+	}{
+		{
+			late: func(ctx context.Context) (Result, error) { <-ctx.Done(); return "", ctx.Err() },
+			want: 3,
+		},
+		{
+			opts: []Option{WithDiscardLateErrors(), WithNoCancelOnSuccess()},
+			late: func(ctx context.Context) (Result, error) { return "", errors.New("late failure") },
+			want: 3,
+		},
+		{
+			opts: []Option{WithNoCancelOnSuccess()},
+			late: func(ctx context.Context) (Result, error) { return "", errors.New("late failure") },
+			want: 0,
+		},
+	}
+	for _, tc := range tests {
+		g, _ := WithContext[Result](context.Background(), tc.opts...)
+		g.Go(func() (Result, error) { return "executor_1", nil })
+		for i := 0; i < 3; i++ {
+			g.GoCtx(func(ctx context.Context) (Result, error) {
+				<-g.winCh
+				return tc.late(ctx)
+			})
+		}
+		g.Wait()
+		if got := g.Discarded(); got != tc.want {
+			t.Errorf("got %d discarded, want %d", got, tc.want)
+		}
+	}
+}
+
+func TestSetScore(t *testing.T) {
+	tests := []struct {
+		results []Result
+		want    Result
+	}{
+		{results: []Result{"b", "dddd", "cc"}, want: "dddd"},
+		{results: []Result{"aa", "bb", "c"}, want: "aa"},
+		{results: []Result{"a"}, want: "a"},
+	}
+	for _, tc := range tests {
+		g, _ := WithContext[Result](context.Background())
+		g.SetScore(func(r Result) float64 { return float64(len(r)) })
+		var onWin Result
+		g.OnWin(func(r Result) { onWin = r })
+		future := g.Future()
+		release := make(chan struct{})
+		for i, r := range tc.results {
+			g.GoCtx(func(ctx context.Context) (Result, error) {
+				if i > 0 {
+					<-release
+				}
+				if ctx.Err() != nil {
+					return "", ctx.Err()
+				}
+				return r, nil
+			})
+		}
+		g.Go(func() (Result, error) { return "", errors.New("executor failed") })
+		for g.Succeeded() < 1 {
+			time.Sleep(time.Millisecond)
+		}
+		if len(tc.results) > 1 && g.Won() {
+			t.Errorf("got a winner, want none before all functions have returned")
+		}
+		got := make(chan Result)
+		go func() {
+			r, _ := future.Get()
+			got <- r
+		}()
+		g.Freeze()
+		close(release)
+		if r := <-got; r != tc.want {
+			t.Errorf("got Future.Get %v, want %v", r, tc.want)
+		}
+		ok, err := g.Wait()
+		if err != nil {
+			t.Fatalf("got %v, want nil error", err)
+		}
+		if onWin != tc.want {
+			t.Errorf("got OnWin %v, want %v", onWin, tc.want)
+		}
+		if ok != tc.want {
+			t.Errorf("got %v, want %v", ok, tc.want)
+		}
+		if n := g.Succeeded(); n != len(tc.results) {
+			t.Errorf("got %d ok responses, want %d", n, len(tc.results))
+		}
+	}
+}
+
+func TestIsPartialFailure(t *testing.T) {
+	errFailed := errors.New("executor failed")
+	ok := func(*Group[Result]) (Result, error) { return "executor", nil }
+	fail := func(*Group[Result]) (Result, error) { return "", errFailed }
+	// lateFail fails once the group has an ok response, as Wait returns
+	// as soon as the quorum is lost.
+	lateFail := func(g *Group[Result]) (Result, error) {
+		for g.Succeeded() < 1 {
+			time.Sleep(time.Millisecond)
+		}
+		return "", errFailed
+	}
+	tests := []struct {
+		opts    []Option
+		fns     []func(*Group[Result]) (Result, error)
+		waitAll bool
+		partial bool
+		total   bool
+	}{
+		{fns: []func(*Group[Result]) (Result, error){fail, fail}, total: true},
+		{fns: []func(*Group[Result]) (Result, error){ok, fail}, waitAll: true, partial: true},
+		{opts: []Option{WithQuorum(2)}, fns: []func(*Group[Result]) (Result, error){ok, lateFail}, partial: true},
+		{fns: []func(*Group[Result]) (Result, error){ok, ok}, waitAll: true},
+	}
+	for _, tc := range tests {
+		g := New[Result](tc.opts...)
+		for _, fn := range tc.fns {
+			fn := fn
+			g.Go(func() (Result, error) { return fn(g) })
+		}
+		var err error
+		if tc.waitAll {
+			_, err = g.WaitAll()
+		} else {
+			_, err = g.Wait()
+		}
+		err = fmt.Errorf("wrapped: %w", err)
+		if got := IsPartialFailure(err); got != tc.partial {
+			t.Errorf("got %t, want %t partial failure for %v", got, tc.partial, err)
+		}
+		if got := IsTotalFailure(err); got != tc.total {
+			t.Errorf("got %t, want %t total failure for %v", got, tc.total, err)
+		}
+	}
+	if IsPartialFailure(errFailed) || IsTotalFailure(errFailed) {
+		t.Error("want neither partial nor total failure for non-group error")
+	}
+}
+
+// recordHandler is a slog.Handler capturing records.
+type recordHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *recordHandler) WithGroup(string) slog.Handler { return h }
+
+func TestSetLogger(t *testing.T) {
+	errFailed := errors.New("executor_1 failed")
+	h := &recordHandler{}
+	g := New[Result](WithName("fetch"))
+	g.SetLogger(slog.New(h))
+	g.Go(func() (Result, error) { return "", errFailed })
+	g.Go(func() (Result, error) {
+		time.Sleep(10 * time.Millisecond)
+		return "executor_2", nil
+	})
+	g.Wait()
+	got := map[string]int{}
+	for _, r := range h.records {
+		got[r.Message]++
+		if r.Level != slog.LevelDebug {
+			t.Errorf("got %v level, want %v", r.Level, slog.LevelDebug)
+		}
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == "error" && a.Value.Any() != errFailed {
+				t.Errorf("got %v, want %v", a.Value, errFailed)
+			}
+			if a.Key == "group" && a.Value.String() != "fetch" {
+				t.Errorf("got %v group, want fetch", a.Value)
+			}
+			return true
+		})
+	}
+	want := map[string]int{
+		"okgroup: function submitted": 2,
+		"okgroup: function returned":  2,
+		"okgroup: function failed":    1,
+		"okgroup: group won":          1,
+		"okgroup: group waited":       1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSeq(t *testing.T) {
+	errFailed := errors.New("executor_3 failed")
+	g := New[Result](WithNoCancelOnSuccess())
+	g.Go(func() (Result, error) { return "executor_1", nil })
+	g.Go(func() (Result, error) { time.Sleep(10 * time.Millisecond); return "executor_2", nil })
+	g.Go(func() (Result, error) { return "", errFailed })
+	var got []Result
+	for ok := range g.Seq() {
+		got = append(got, ok)
+	}
+	if want := []Result{"executor_1", "executor_2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if err := g.Err(); !errors.Is(err, errFailed) {
+		t.Errorf("got %v, want %v", err, errFailed)
+	}
+	for ok := range g.Seq() {
+		if ok != "executor_1" {
+			t.Errorf("got %v, want executor_1", ok)
+		}
+		break
+	}
+}
+
+type codeError struct {
+	code int
+}
+
+func (e *codeError) Error() string {
+	return fmt.Sprintf("executor failed with code %d", e.code)
+}
+
+func TestError_Unwrap(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		g := New[Result]()
+		for j := 0; j < 3; j++ {
+			j := j
+			g.Go(func() (Result, error) {
+				if j == i {
+					return "", &codeError{code: j}
+				}
+				return "", fmt.Errorf("executor_%d failed", j)
+			})
+		}
+		_, err := g.Wait()
+		for _, err := range []error{err, fmt.Errorf("fetching: %w", err), err.(Error).Wrap("fetching")} {
+			var target *codeError
+			if !errors.As(err, &target) || target.code != i {
+				t.Errorf("got %v, want *codeError with code %d", target, i)
+			}
+			var goerr GoroutineError
+			if !errors.As(err, &goerr) {
+				t.Errorf("want %v to be GoroutineError", err)
+			}
+		}
+		unwrapper, ok := err.(interface{ Unwrap() []error })
+		if !ok {
+			t.Fatal("want Unwrap() []error")
+		}
+		if got := len(unwrapper.Unwrap()); got != 3 {
+			t.Errorf("got %d errors, want 3", got)
+		}
+	}
+}
+
+func TestSeq2(t *testing.T) {
+	errFailed := errors.New("executor_2 failed")
+	g := New[Result](WithNoCancelOnSuccess())
+	g.Go(func() (Result, error) { return "executor_1", nil })
+	<-g.winCh
+	release := make(chan struct{})
+	g.Go(func() (Result, error) { <-release; return "", errFailed })
+	g.Go(func() (Result, error) { <-release; time.Sleep(10 * time.Millisecond); return "executor_3", nil })
+	var oks []Result
+	var errs []error
+	for ok, err := range g.Seq2() {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		oks = append(oks, ok)
+		if len(oks) == 1 {
+			close(release)
+		}
+	}
+	if want := []Result{"executor_1", "executor_3"}; !reflect.DeepEqual(oks, want) {
+		t.Errorf("got %v, want %v", oks, want)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], errFailed) {
+		t.Errorf("got %v, want %v", errs, errFailed)
+	}
+	var n int
+	for range g.Seq2() {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("got %d outcomes, want 1", n)
+	}
+}
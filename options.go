@@ -0,0 +1,472 @@
+package okgroup
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// An Option configures a Group at construction time.
+//
+// Options are not parameterized by the group's type, so the generic ones,
+// such as WithDefault or WithOnWin, carry a value of their own type parameter
+// which is checked against the group's type T only when the group is created:
+// New, WithContext and the other constructors panic if the types differ.
+// The compiler infers the option's type parameter from its argument, not from
+// the group, so instantiate it explicitly when they may differ, as in
+// New[MyString](WithDefault[MyString]("fallback")).
+type Option func(*options)
+
+type options struct {
+	name              string
+	fallback          any
+	hasFallback       bool
+	cancelOnFailure   bool
+	noCancelOnSuccess bool
+	limit             int
+	maxGoroutines     int
+	sem               chan struct{}
+	quorum            int
+	isOK              any
+	keepPartial       bool
+	onWin             any
+	onCancel          func()
+	onPanic           func(index int, recovered any) error
+	taps              []any
+	score             any
+	logger            *slog.Logger
+	breakerThreshold  int
+	breakerReset      time.Duration
+	progress          chan<- float64
+	indexedErrors     bool
+	cache             any
+	errBuffer         int
+	pool              GoroutinePool
+	spanner           func(ctx context.Context, index int, name string) (context.Context, func())
+	propagate         []any
+	results           bool
+	resultBuffer      int
+	discardLateErrors bool
+	metrics           Metrics
+}
+
+// WithName labels the group with the name, which identifies the group
+// in the result of its String method and in panics of WaitMust.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithDefault configures a fallback value returned by Wait along with
+// a nil error if all functions fail.
+//
+// Use Succeeded to tell the fallback value apart from an ok response.
+// A nil fallback, such as WithDefault[error](nil), is a fallback value as any other.
+//
+// The fallback value must be of the group's type T, the group's constructor
+// panics otherwise. An untyped constant gets its default type, so
+// WithDefault("fallback") does not fit a Group[MyString], use
+// WithDefault[MyString]("fallback") instead.
+func WithDefault[T any](fallback T) Option {
+	return func(o *options) {
+		o.fallback = fallback
+		o.hasFallback = true
+	}
+}
+
+// WithCancelOnFirstFailure configures the group to cancel its context
+// the first time a function returns an error.
+//
+// It does not affect canceling the context by an ok response.
+// The error which canceled the context is available by calling Error.Cause.
+func WithCancelOnFirstFailure() Option {
+	return func(o *options) {
+		o.cancelOnFailure = true
+	}
+}
+
+// WithNoCancelOnSuccess configures the group not to cancel its context
+// when a function returns an ok response.
+//
+// It is intended for collecting all ok responses with WaitAll,
+// the group's context is still canceled the first time Wait returns.
+func WithNoCancelOnSuccess() Option {
+	return func(o *options) {
+		o.noCancelOnSuccess = true
+	}
+}
+
+// WithDiscardLateErrors configures the group to drop errors of functions
+// returning after the group has an ok response, instead of keeping them
+// for diagnostics. Such errors are neither part of Errors nor delivered by Results.
+func WithDiscardLateErrors() Option {
+	return func(o *options) {
+		o.discardLateErrors = true
+	}
+}
+
+// WithLimit limits the number of active goroutines in the group to n.
+//
+// Go blocks until it can start a new goroutine without exceeding the limit.
+// If the group's context is done while Go is blocked, the function is skipped.
+// A non-positive n means no limit.
+func WithLimit(n int) Option {
+	return func(o *options) {
+		o.limit = n
+	}
+}
+
+// WithMaxGoroutines caps the total number of goroutines the group ever runs to n.
+//
+// Unlike WithLimit, which caps the number of goroutines running at once,
+// the cap does not free up once a function returns. It is intended for producers
+// of unbounded inputs which need a backpressure signal: once n functions have been
+// passed to the group, TryGo returns ErrGroupFull and passing a function by any
+// other method panics, as for a frozen group. A backup copy of GoHedge over the cap
+// is not executed. Both options can be combined. A non-positive n means no cap.
+func WithMaxGoroutines(n int) Option {
+	return func(o *options) {
+		o.maxGoroutines = n
+	}
+}
+
+// WithSemaphore limits the number of active goroutines in the group
+// by acquiring the semaphore sem before starting each goroutine.
+//
+// Unlike WithLimit, the semaphore is managed by the caller, so it can be shared
+// across multiple groups to limit their total number of active goroutines.
+// The capacity of sem is the limit. WithSemaphore takes precedence over WithLimit.
+// As with WithLimit, Go skips the function if the group's context is done.
+func WithSemaphore(sem chan struct{}) Option {
+	return func(o *options) {
+		o.sem = sem
+	}
+}
+
+// WithQuorum configures the group to require n ok responses to succeed.
+//
+// The group's context is canceled once the n-th ok response is returned
+// instead of the first one. Wait returns the first ok response if the quorum
+// is reached, otherwise the group's error containing ErrNoQuorum.
+// Wait returns as soon as too many functions have failed for the quorum
+// to be reached, the group's context is then canceled.
+// A non-positive n means the default quorum of 1.
+func WithQuorum(n int) Option {
+	return func(o *options) {
+		o.quorum = n
+	}
+}
+
+// WithOK configures a predicate telling whether a value returned along with
+// a nil error is an ok response.
+//
+// A value rejected by the predicate is treated as a failure with ErrNotOK.
+//
+// The predicate's parameter must be of the group's type T, a predicate
+// of another type makes the group's constructor panic, see Option.
+func WithOK[T any](isOK func(T) bool) Option {
+	return func(o *options) {
+		o.isOK = isOK
+	}
+}
+
+// WithKeepPartial configures the group to keep non-zero values
+// returned along with a non-nil error.
+//
+// Such values are not ok responses, but a best-effort results
+// available by calling Partials.
+func WithKeepPartial() Option {
+	return func(o *options) {
+		o.keepPartial = true
+	}
+}
+
+// WithOnWin configures a callback invoked with the ok response
+// as soon as the group has one, before Wait returns.
+//
+// The callback is invoked exactly once from the goroutine producing the first
+// ok response and never if all functions fail.
+//
+// As the callback is checked at run time, a callback of a type other than
+// func(T), for the group's type T, compiles but makes the group's constructor panic.
+func WithOnWin[T any](f func(T)) Option {
+	return func(o *options) {
+		o.onWin = f
+	}
+}
+
+// WithOnCancel configures a callback invoked when the group cancels its context,
+// either because of an ok response or the first time Wait returns.
+//
+// The callback is invoked exactly once, also for a group created by calling New.
+// It allows to release resources which cannot observe the context.
+func WithOnCancel(f func()) Option {
+	return func(o *options) {
+		o.onCancel = f
+	}
+}
+
+// WithOnPanic configures a callback invoked with the goroutine's index and
+// the recovered value if a function passed to the group panics.
+//
+// The error returned by the callback is the function's error.
+// If the callback returns nil, the panic is swallowed and the function
+// produces neither an ok response nor an error. By default a panic
+// is converted to a PanicError.
+func WithOnPanic(f func(index int, recovered any) error) Option {
+	return func(o *options) {
+		o.onPanic = f
+	}
+}
+
+// WithTap registers an observer invoked with the result of every function call
+// from the Go method, before the result is recorded by the group.
+//
+// Observers must not block. A panicking observer does not affect the group,
+// the panic is discarded. The option can be passed multiple times,
+// observers are invoked in order.
+//
+// Each observer must be a func(T, error) for the group's type T,
+// otherwise creating the group panics, see Option.
+func WithTap[T any](fn func(T, error)) Option {
+	return func(o *options) {
+		o.taps = append(o.taps, fn)
+	}
+}
+
+// WithScore configures the group to pick the ok response maximizing score
+// instead of the first one.
+//
+// The group no longer cancels its context on the first ok response,
+// Wait blocks until all functions have returned and returns the ok response
+// with the highest score, the earliest one on a tie. The group wins only then:
+// Won, Future.Get, WaitThenDrain and the callback configured with WithOnWin
+// wait for Wait or, for a frozen group, for all functions to return.
+// WaitWithTimeout and TryResult return the best ok response so far.
+//
+// score must be a func(T) float64 for the group's type T. A mismatch is
+// only detected when the group is created, by a panic.
+func WithScore[T any](score func(T) float64) Option {
+	return func(o *options) {
+		o.score = score
+	}
+}
+
+// WithLogger configures the group to log its lifecycle events at the debug level:
+// passing, return and failure of functions, the ok response and the return of Wait.
+//
+// Records carry the function's index, duration and error as attributes.
+// By default the group does not log.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithCircuitBreaker configures a circuit breaker which opens once
+// functions passed to the group have failed failureThreshold times.
+//
+// While the breaker is open, Go does not execute functions, they fail
+// immediately with ErrCircuitOpen. The breaker resets once resetTimeout
+// has elapsed since the last failure.
+func WithCircuitBreaker(failureThreshold int, resetTimeout time.Duration) Option {
+	return func(o *options) {
+		o.breakerThreshold = failureThreshold
+		o.breakerReset = resetTimeout
+	}
+}
+
+// WithProgressChan configures a channel receiving the group's progress
+// each time a function call from the Go method returns.
+//
+// The progress is the number of returned function calls divided by the number
+// of functions passed to the group so far, it is 1.0 once all of them returned.
+// Sends never block, the progress is skipped if ch is not ready to receive.
+func WithProgressChan(ch chan<- float64) Option {
+	return func(o *options) {
+		o.progress = ch
+	}
+}
+
+// WithIndexedErrors configures the group to annotate errors with the index
+// of the goroutine which returned them, e.g. "okgroup[2]: timeout".
+//
+// The annotated errors wrap the original ones, so errors.Is and errors.As
+// keep working.
+func WithIndexedErrors() Option {
+	return func(o *options) {
+		o.indexedErrors = true
+	}
+}
+
+// WithErrorBuffer limits the number of errors retained by the group to n.
+//
+// By default the group retains all errors, which allows to inspect every
+// failure, but makes memory usage grow with the number of failing functions.
+// Once n errors are retained, errors of subsequent failing functions are
+// dropped, they are neither part of the group's error nor returned by Errors,
+// nor delivered by Results or yielded by Seq2.
+// A non-positive n means no limit.
+func WithErrorBuffer(n int) Option {
+	return func(o *options) {
+		o.errBuffer = n
+	}
+}
+
+// WithGoroutinePool configures the group to execute functions passed to Go
+// by submitting them to the pool instead of starting new goroutines.
+//
+// The backup copy of a function passed to GoHedge is still executed
+// in a new goroutine.
+func WithGoroutinePool(pool GoroutinePool) Option {
+	return func(o *options) {
+		o.pool = pool
+	}
+}
+
+// WithSpanner configures the group to derive a context for each goroutine
+// by calling the spanner with the group's context, the goroutine's index and its name
+// given by GoNamed or GoKeyed, which is empty for other goroutines.
+// The function returned by the spanner is called once the goroutine's function returns.
+//
+// The spanner allows to run each goroutine under its own tracing span,
+// the derived context is passed to functions submitted with GoCtx or GoHedge.
+// See the okgroup/otel package for OpenTelemetry support.
+func WithSpanner(spanner func(ctx context.Context, index int, name string) (context.Context, func())) Option {
+	return func(o *options) {
+		o.spanner = spanner
+	}
+}
+
+// WithContextPropagation configures the group to copy the values of the keys
+// from the group's context into each goroutine's context, right before
+// passing it to the function.
+//
+// It keeps request-scoped values, such as auth tokens or trace IDs, available
+// to functions even if the goroutine's context was replaced, for example by the spanner
+// configured with WithSpanner. Keys without a value in the group's context are skipped.
+func WithContextPropagation(keys ...any) Option {
+	return func(o *options) {
+		o.propagate = append(o.propagate, keys...)
+	}
+}
+
+// WithResults configures the group to deliver the outcome of each function
+// over the channel returned by Results.
+//
+// Functions block until their outcomes are received,
+// so the channel has to be drained for the group to complete.
+func WithResults() Option {
+	return func(o *options) {
+		o.results = true
+	}
+}
+
+// WithResultBuffer is like WithResults but buffers up to n outcomes
+// not yet received from the channel returned by Results.
+//
+// Once the buffer is full, functions block until outcomes are received,
+// which bounds the memory used by outcomes if the consumer is slower than functions.
+func WithResultBuffer(n int) Option {
+	return func(o *options) {
+		o.results = true
+		o.resultBuffer = n
+	}
+}
+
+// WithMetrics configures the group to report its metrics to m.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// WithCache configures a cache memoizing ok responses of functions passed to GoKeyed.
+//
+// Errors are never cached.
+//
+// The cache must be a Cache[T] of the group's type T. The check happens
+// when the group is created, which panics on a mismatch, see Option.
+func WithCache[T any](cache Cache[T]) Option {
+	return func(o *options) {
+		o.cache = cache
+	}
+}
+
+// apply configures g with the given options.
+func (g *Group[T]) apply(opts []Option) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	g.cancelOnFailure = o.cancelOnFailure
+	g.noCancelOnSuccess = o.noCancelOnSuccess
+	g.keepPartial = o.keepPartial
+	g.breakerThreshold = o.breakerThreshold
+	g.breakerReset = o.breakerReset
+	g.progress = o.progress
+	g.indexedErrors = o.indexedErrors
+	g.errBuffer = o.errBuffer
+	g.pool = o.pool
+	g.name = o.name
+	g.spanner = o.spanner
+	g.propagate = o.propagate
+	g.discardLateErrors = o.discardLateErrors
+	g.metrics = NopMetrics{}
+	if o.metrics != nil {
+		g.metrics = o.metrics
+	}
+	if o.results {
+		g.results = make(chan Outcome[T], o.resultBuffer)
+	}
+	if o.hasFallback {
+		// A nil interface fallback loses its type in o.fallback, it is T's zero value.
+		var fallback T
+		if o.fallback != nil {
+			fallback = typed[T, T]("WithDefault", o.fallback)
+		}
+		g.fallback = &fallback
+	}
+	if o.sem != nil {
+		g.sem = o.sem
+	} else if o.limit > 0 {
+		g.sem = make(chan struct{}, o.limit)
+	}
+	if o.maxGoroutines > 0 {
+		g.maxGoroutines = int32(o.maxGoroutines)
+	}
+	if o.quorum > 0 {
+		g.quorum = o.quorum
+	}
+	if o.isOK != nil {
+		g.isOK = typed[func(T) bool, T]("WithOK", o.isOK)
+	}
+	if o.cache != nil {
+		g.cache = typed[Cache[T], T]("WithCache", o.cache)
+	}
+	if o.onWin != nil {
+		g.onWin = typed[func(T), T]("WithOnWin", o.onWin)
+	}
+	g.onCancel = o.onCancel
+	g.onPanic = o.onPanic
+	for _, tap := range o.taps {
+		g.taps = append(g.taps, typed[func(T, error), T]("WithTap", tap))
+	}
+	if o.score != nil {
+		g.score = typed[func(T) float64, T]("WithScore", o.score)
+	}
+	g.logger = o.logger
+}
+
+// typed asserts that the value of the option is of type V,
+// where T is the group's type.
+func typed[V, T any](option string, v any) V {
+	tv, ok := v.(V)
+	if !ok {
+		var t T
+		panic(fmt.Sprintf("okgroup: %s value of type %T used with Group[%T], instantiate the option with the group's type", option, v, t))
+	}
+	return tv
+}
@@ -0,0 +1,728 @@
+package okgroup
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithDefault(t *testing.T) {
+	tests := []struct {
+		name          string
+		fn            func() (Result, error)
+		want          Result
+		wantSucceeded bool
+	}{
+		{name: "ok response", fn: func() (Result, error) { return "executor_1", nil }, want: "executor_1", wantSucceeded: true},
+		{name: "error", fn: func() (Result, error) { return "", errors.New("executor_1 failed") }, want: "fallback"},
+	}
+	for _, tc := range tests {
+		g := New[Result](WithDefault(Result("fallback")))
+		g.Go(tc.fn)
+		got, err := g.Wait()
+		if err != nil {
+			t.Fatalf("%s: want nil err, got %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+		if g.Won() != tc.wantSucceeded {
+			t.Errorf("%s: got succeeded %v, want %v", tc.name, g.Won(), tc.wantSucceeded)
+		}
+	}
+}
+
+func TestWithDefault_TypeMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("want panic")
+		}
+	}()
+	New[Result](WithDefault("fallback"))
+}
+
+func TestWithDefault_Nil(t *testing.T) {
+	g := New[error](WithDefault[error](nil))
+	g.Go(func() (error, error) { return nil, errors.New("executor_1 failed") })
+	got, err := g.Wait()
+	if got != nil || err != nil {
+		t.Errorf("got %v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestWithCancelOnFirstFailure(t *testing.T) {
+	errFailed := errors.New("executor_1 failed")
+	g, ctx := WithContext[Result](context.Background(), WithCancelOnFirstFailure())
+	g.Go(func() (Result, error) { return "", errFailed })
+	g.Go(func() (Result, error) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(time.Second):
+			return "executor_2", nil
+		}
+	})
+	_, err := g.Wait()
+	grouperr, ok := AsGroupError(err)
+	if !ok {
+		t.Fatalf("want Error, got %v", err)
+	}
+	if !errors.Is(grouperr.Cause(), errFailed) {
+		t.Errorf("got cause %v, want %v", grouperr.Cause(), errFailed)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got err %v, want err %v", err, context.Canceled)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	errFailed := errors.New("executor failed")
+	ok := func(r Result) func() (Result, error) {
+		return func() (Result, error) { time.Sleep(time.Millisecond * 10); return r, nil }
+	}
+	fail := func() (Result, error) { time.Sleep(time.Millisecond * 10); return "", errFailed }
+	tests := []struct {
+		name    string
+		opts    []Option
+		fns     []func() (Result, error)
+		want    Result
+		wanterr []error
+	}{
+		{
+			name: "quorum reached",
+			opts: []Option{WithQuorum(2)},
+			fns:  []func() (Result, error){ok("executor_1"), fail, ok("executor_1")},
+			want: "executor_1",
+		},
+		{
+			name:    "quorum not reached",
+			opts:    []Option{WithQuorum(2), WithLimit(1)},
+			fns:     []func() (Result, error){ok("executor_1"), fail, fail},
+			wanterr: []error{errFailed, ErrNoQuorum},
+		},
+		{
+			name: "ok predicate",
+			opts: []Option{WithOK(func(r Result) bool { return r != "stale" }), WithLimit(2)},
+			fns:  []func() (Result, error){ok("stale"), ok("fresh")},
+			want: "fresh",
+		},
+		{
+			name:    "ok predicate rejects all",
+			opts:    []Option{WithOK(func(r Result) bool { return r != "stale" }), WithQuorum(1)},
+			fns:     []func() (Result, error){ok("stale")},
+			wanterr: []error{ErrNotOK},
+		},
+		{
+			name: "ok predicate with fallback",
+			opts: []Option{WithOK(func(r Result) bool { return r != "stale" }), WithDefault(Result("fallback"))},
+			fns:  []func() (Result, error){ok("stale"), fail},
+			want: "fallback",
+		},
+	}
+	for _, tc := range tests {
+		g, _ := WithContext[Result](context.Background(), tc.opts...)
+		for _, fn := range tc.fns {
+			g.Go(fn)
+		}
+		got, err := g.Wait()
+		if (err != nil) != (len(tc.wanterr) > 0) {
+			t.Fatalf("%s: got err %v, want errors %v", tc.name, err, tc.wanterr)
+		}
+		for _, wanterr := range tc.wanterr {
+			if !errors.Is(err, wanterr) {
+				t.Errorf("%s: got err %v, want err %v", tc.name, err, wanterr)
+			}
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWithLimit(t *testing.T) {
+	var active, max int32
+	g := New[Result](WithLimit(2))
+	for i := 0; i < 6; i++ {
+		g.Go(func() (Result, error) {
+			n := atomic.AddInt32(&active, 1)
+			for {
+				m := atomic.LoadInt32(&max)
+				if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond * 10)
+			atomic.AddInt32(&active, -1)
+			return "executor", nil
+		})
+	}
+	g.Wait()
+	if max := atomic.LoadInt32(&max); max > 2 {
+		t.Errorf("got %d active goroutines, want at most 2", max)
+	}
+}
+
+func TestWithKeepPartial_WithOnWin(t *testing.T) {
+	var won []Result
+	g := New[Result](WithKeepPartial(), WithOnWin(func(r Result) { won = append(won, r) }))
+	g.Go(func() (Result, error) { return "partial", errors.New("executor_1 failed") })
+	g.Go(func() (Result, error) { time.Sleep(time.Millisecond * 10); return "executor_2", nil })
+	g.Wait()
+	if partials := g.Partials(); len(partials) != 1 || partials[0] != "partial" {
+		t.Errorf("got partials %v, want [partial]", partials)
+	}
+	if len(won) != 1 || won[0] != "executor_2" {
+		t.Errorf("got won %v, want [executor_2]", won)
+	}
+}
+
+func TestWithCallbacks(t *testing.T) {
+	var mu sync.Mutex
+	var taps []Result
+	var canceled, panicked int
+	h := &recordHandler{}
+	g := New[Result](
+		WithNoCancelOnSuccess(),
+		WithTap(func(r Result, _ error) { mu.Lock(); taps = append(taps, r); mu.Unlock() }),
+		WithOnCancel(func() { canceled++ }),
+		WithOnPanic(func(int, any) error { mu.Lock(); panicked++; mu.Unlock(); return nil }),
+		WithScore(func(r Result) float64 { return float64(len(r)) }),
+		WithLogger(slog.New(h)),
+	)
+	g.Go(func() (Result, error) { return "short", nil })
+	g.Go(func() (Result, error) { time.Sleep(10 * time.Millisecond); return "the longest", nil })
+	g.Go(func() (Result, error) { panic("executor_3") })
+	got, err := g.Wait()
+	if got != "the longest" || err != nil {
+		t.Errorf("got %v, %v, want the longest, nil", got, err)
+	}
+	if len(taps) != 2 || panicked != 1 || canceled != 1 {
+		t.Errorf("got %d taps, %d panics and %d cancels, want 2, 1 and 1", len(taps), panicked, canceled)
+	}
+	if len(h.records) == 0 {
+		t.Error("want records logged")
+	}
+}
+
+func TestWithScore_TypeMismatch(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("want panic")
+		}
+	}()
+	New[Result](WithScore(func(int) float64 { return 0 }))
+}
+
+func TestWithNoCancelOnSuccess(t *testing.T) {
+	g, ctx := WithContext[Result](context.Background(), WithNoCancelOnSuccess())
+	g.Go(func() (Result, error) { return "executor_1", nil })
+	g.Go(func() (Result, error) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(time.Millisecond * 50):
+			return "executor_2", nil
+		}
+	})
+	got, err := g.WaitAll()
+	if err != nil {
+		t.Fatalf("want nil err, got %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("got %v, want 2 ok responses", got)
+	}
+	select {
+	case <-ctx.Done():
+	default:
+		t.Errorf("want ctx canceled")
+	}
+}
+
+func TestWithSemaphore(t *testing.T) {
+	var active, max int32
+	fn := func() (Result, error) {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond * 10)
+		atomic.AddInt32(&active, -1)
+		return "executor", nil
+	}
+	sem := make(chan struct{}, 2)
+	g1, g2 := New[Result](WithSemaphore(sem)), New[Result](WithSemaphore(sem), WithLimit(10))
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 4; i++ {
+			g2.Go(fn)
+		}
+		close(done)
+	}()
+	for i := 0; i < 4; i++ {
+		g1.Go(fn)
+	}
+	<-done
+	g1.Wait()
+	g2.Wait()
+	if max := atomic.LoadInt32(&max); max > 2 {
+		t.Errorf("got %d active goroutines, want at most 2", max)
+	}
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	var calls int32
+	fail := func() (Result, error) { atomic.AddInt32(&calls, 1); return "", errors.New("executor failed") }
+	g := New[Result](WithCircuitBreaker(2, time.Millisecond*50))
+	g.Go(fail)
+	g.Go(fail)
+	for len(g.Errors()) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	g.Go(fail)
+	if errs := g.Errors(); len(errs) != 3 || !errors.Is(errs[2], ErrCircuitOpen) {
+		t.Errorf("got errs %v, want %v", errs, ErrCircuitOpen)
+	}
+	time.Sleep(time.Millisecond * 60)
+	g.Go(func() (Result, error) { atomic.AddInt32(&calls, 1); return "executor", nil })
+	got, err := g.Wait()
+	if err != nil {
+		t.Fatalf("want nil err, got %v", err)
+	}
+	if got != "executor" {
+		t.Errorf("got %v, want executor", got)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 3 {
+		t.Errorf("got %d calls, want 3", calls)
+	}
+}
+
+func TestWithProgressChan(t *testing.T) {
+	progress := make(chan float64, 4)
+	g := New[Result](WithProgressChan(progress))
+	for i := 0; i < 4; i++ {
+		g.Go(func() (Result, error) { return "executor", nil })
+	}
+	g.Wait()
+	close(progress)
+	var got []float64
+	var completed bool
+	for p := range progress {
+		got = append(got, p)
+		completed = completed || p == 1.0
+	}
+	if len(got) != 4 || !completed {
+		t.Errorf("got %v, want 4 values including 1.0", got)
+	}
+}
+
+func TestWithLimit_CanceledWhileQueued(t *testing.T) {
+	var calls int32
+	g, ctx := WithContext[Result](context.Background(), WithLimit(1))
+	g.Go(func() (Result, error) { time.Sleep(time.Millisecond * 10); return "executor_1", nil })
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			g.Go(func() (Result, error) {
+				atomic.AddInt32(&calls, 1)
+				<-ctx.Done()
+				return "", ctx.Err()
+			})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("want queued Go calls unblocked")
+	}
+	got, err := g.Wait()
+	if err != nil {
+		t.Fatalf("want nil err, got %v", err)
+	}
+	if got != "executor_1" {
+		t.Errorf("got %v, want executor_1", got)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 0 {
+		t.Errorf("got %d calls, want 0", calls)
+	}
+}
+
+func TestWithIndexedErrors(t *testing.T) {
+	err1, err2 := errors.New("executor_1 failed"), errors.New("executor_3 failed")
+	g := New[Result](WithIndexedErrors(), WithLimit(1))
+	g.Go(func() (Result, error) { return "", err1 })
+	g.Go(func() (Result, error) { return "executor_2", nil })
+	g.Go(func() (Result, error) { return "", err2 })
+	_, err := g.WaitAll()
+	if want := "okgroup[0]: executor_1 failed;okgroup[2]: executor_3 failed"; err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+	for _, wanterr := range []error{err1, err2} {
+		if !errors.Is(err, wanterr) {
+			t.Errorf("got err %v, want err %v", err, wanterr)
+		}
+	}
+	var goerr GoroutineError
+	if !errors.As(err, &goerr) || goerr.Index != 0 {
+		t.Errorf("got %+v, want GoroutineError with Index 0", goerr)
+	}
+}
+
+type mapCache struct {
+	mu sync.Mutex
+	m  map[string]Result
+}
+
+func (c *mapCache) Get(key string) (Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.m[key]
+	return v, ok
+}
+
+func (c *mapCache) Set(key string, val Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m[key] = val
+}
+
+func TestWithCache(t *testing.T) {
+	var calls int32
+	cache := &mapCache{m: map[string]Result{}}
+	for i := 0; i < 2; i++ {
+		g := New[Result](WithCache[Result](cache))
+		g.GoKeyed("user", func() (Result, error) { atomic.AddInt32(&calls, 1); return "user", nil })
+		g.GoKeyed("failing", func() (Result, error) { atomic.AddInt32(&calls, 1); return "", errors.New("executor failed") })
+		if got, err := g.Wait(); err != nil || got != "user" {
+			t.Errorf("got %v, %v, want user, nil", got, err)
+		}
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 3 {
+		t.Errorf("got %d calls, want 3", calls)
+	}
+	if _, ok := cache.Get("failing"); ok {
+		t.Errorf("want error not cached")
+	}
+}
+
+func TestWithCache_Results(t *testing.T) {
+	cache := &mapCache{m: map[string]Result{"user": "cached"}}
+	g := New[Result](WithCache[Result](cache), WithResults())
+	submitted := make(chan struct{})
+	go func() {
+		g.GoKeyed("user", func() (Result, error) { return "user", nil })
+		close(submitted)
+	}()
+	select {
+	case <-submitted:
+	case <-time.After(time.Second):
+		t.Fatal("want GoKeyed not blocked until the outcome is received")
+	}
+	if got := <-g.Results(); got.Value != "cached" {
+		t.Errorf("got %v, want cached", got.Value)
+	}
+	g.Wait()
+}
+
+func TestWithErrorBuffer(t *testing.T) {
+	g := New[Result](WithErrorBuffer(2))
+	for i := 0; i < 5; i++ {
+		g.Go(func() (Result, error) { return "", errors.New("executor failed") })
+	}
+	_, err := g.Wait()
+	if got := len(err.(Error).errors); got != 2 {
+		t.Errorf("got %d errors, want 2", got)
+	}
+	if got := len(g.Errors()); got != 2 {
+		t.Errorf("got %d errors, want 2", got)
+	}
+}
+
+type countingPool struct {
+	submitted int32
+	capacity  int32
+}
+
+func (p *countingPool) Submit(task func()) error {
+	if atomic.AddInt32(&p.submitted, 1) > p.capacity {
+		return errPoolFull
+	}
+	go task()
+	return nil
+}
+
+var errPoolFull = errors.New("pool is full")
+
+func TestWithGoroutinePool(t *testing.T) {
+	pool := &countingPool{capacity: 2}
+	g := New[Result](WithGoroutinePool(pool))
+	g.Go(func() (Result, error) { return "executor_1", nil })
+	g.Go(func() (Result, error) { return "executor_2", nil })
+	g.Go(func() (Result, error) { return "executor_3", nil })
+	oks, err := g.WaitAll()
+	if !errors.Is(err, errPoolFull) {
+		t.Errorf("got err %v, want err %v", err, errPoolFull)
+	}
+	if len(oks) != 2 {
+		t.Errorf("got %v, want 2 ok responses", oks)
+	}
+	if submitted := atomic.LoadInt32(&pool.submitted); submitted != 3 {
+		t.Errorf("got %d submitted functions, want 3", submitted)
+	}
+}
+
+func TestWithSpanner(t *testing.T) {
+	type spanKey struct{}
+	var mu sync.Mutex
+	ended := make(map[int]bool)
+	spanner := func(ctx context.Context, index int, name string) (context.Context, func()) {
+		return context.WithValue(ctx, spanKey{}, index), func() {
+			mu.Lock()
+			ended[index] = true
+			mu.Unlock()
+		}
+	}
+	g := New[int](WithSpanner(spanner))
+	g.GoCtx(func(ctx context.Context) (int, error) {
+		return 0, errors.New("executor_1 failed")
+	})
+	g.GoCtx(func(ctx context.Context) (int, error) {
+		index, _ := ctx.Value(spanKey{}).(int)
+		return index, nil
+	})
+	got, err := g.Wait()
+	if err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	if got != 1 {
+		t.Errorf("got index %d, want 1", got)
+	}
+	if !ended[0] || !ended[1] {
+		t.Errorf("got ended spans %v, want both spans ended", ended)
+	}
+}
+
+func TestWithSpanner_Name(t *testing.T) {
+	var mu sync.Mutex
+	names := make(map[int]string)
+	spanner := func(ctx context.Context, index int, name string) (context.Context, func()) {
+		mu.Lock()
+		names[index] = name
+		mu.Unlock()
+		return ctx, func() {}
+	}
+	g := New[int](WithSpanner(spanner), WithNoCancelOnSuccess())
+	g.GoNamed("primary", func() (int, error) { return 0, nil })
+	g.GoKeyed("user:1", func() (int, error) { return 1, nil })
+	g.Go(func() (int, error) { return 2, nil })
+	g.WaitAll()
+	if want := map[int]string{0: "primary", 1: "user:1", 2: ""}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got names %v, want %v", names, want)
+	}
+}
+
+func TestWithQuorum_Impossible(t *testing.T) {
+	g, ctx := WithContext[Result](context.Background(), WithQuorum(3))
+	err1, err2 := errors.New("executor_1 failed"), errors.New("executor_2 failed")
+	slow := func() (Result, error) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(time.Second):
+			return "executor", nil
+		}
+	}
+	g.Go(func() (Result, error) { return "", err1 })
+	g.Go(func() (Result, error) { return "", err2 })
+	g.Go(slow)
+	g.Go(slow)
+	start := time.Now()
+	_, err := g.Wait()
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("got Wait returning after %v, want prompt return", elapsed)
+	}
+	if !errors.Is(err, err1) || !errors.Is(err, err2) {
+		t.Errorf("got err %v, want err containing %v and %v", err, err1, err2)
+	}
+	select {
+	case <-ctx.Done():
+	default:
+		t.Errorf("want ctx canceled")
+	}
+}
+
+func TestWithDiscardLateErrors(t *testing.T) {
+	tests := []struct {
+		opts []Option
+		want int
+	}{
+		{want: 1},
+		{opts: []Option{WithDiscardLateErrors()}, want: 0},
+	}
+	for _, tc := range tests {
+		g := New[Result](tc.opts...)
+		g.Go(func() (Result, error) { return "executor_1", nil })
+		g.Go(func() (Result, error) {
+			for !g.Won() {
+				time.Sleep(time.Millisecond)
+			}
+			return "", errors.New("executor_2 failed")
+		})
+		if _, err := g.Wait(); err != nil {
+			t.Fatalf("want nil err, got %v", err)
+		}
+		if got := len(g.Errors()); got != tc.want {
+			t.Errorf("got %d errors, want %d", got, tc.want)
+		}
+	}
+}
+
+func BenchmarkWithDiscardLateErrors(b *testing.B) {
+	ok := func() (Result, error) { return "executor", nil }
+	fail := func() (Result, error) { return "", errors.New("executor failed") }
+	for i := 0; i < b.N; i++ {
+		g := New[Result](WithDiscardLateErrors())
+		g.Go(ok)
+		for j := 0; j < 100; j++ {
+			g.Go(fail)
+		}
+		g.Wait()
+	}
+}
+
+func TestWithMaxGoroutines(t *testing.T) {
+	g := New[Result](WithMaxGoroutines(2))
+	fn := func() (Result, error) { return "executor", nil }
+	for i := 0; i < 2; i++ {
+		if err := g.TryGo(fn); err != nil {
+			t.Fatalf("want nil err, got %v", err)
+		}
+	}
+	// The cap caps the total number of goroutines, not the running ones.
+	for g.Succeeded() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	if err := g.TryGo(fn); err != ErrGroupFull {
+		t.Errorf("got err %v, want err %v", err, ErrGroupFull)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("want Go to panic once the group is full")
+			}
+		}()
+		g.Go(fn)
+	}()
+	if _, err := g.Wait(); err != nil {
+		t.Errorf("want nil err, got %v", err)
+	}
+	if got := g.Submitted(); got != 2 {
+		t.Errorf("got %d submitted, want 2", got)
+	}
+}
+
+func TestWithMaxGoroutines_Limit(t *testing.T) {
+	g := New[Result](WithMaxGoroutines(2), WithLimit(1))
+	release := make(chan struct{})
+	fn := func() (Result, error) { <-release; return "executor", nil }
+	if err := g.TryGo(fn); err != nil {
+		t.Fatalf("want nil err, got %v", err)
+	}
+	if err := g.TryGo(fn); err != ErrGroupFull {
+		t.Errorf("got err %v, want err %v", err, ErrGroupFull)
+	}
+	close(release)
+	for g.Succeeded() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	// The rejection by the limit did not count against the cap.
+	g.Go(fn)
+	if err := g.TryGo(fn); err != ErrGroupFull {
+		t.Errorf("got err %v, want err %v", err, ErrGroupFull)
+	}
+	g.Wait()
+	if got := g.Submitted(); got != 2 {
+		t.Errorf("got %d submitted, want 2", got)
+	}
+}
+
+func TestWithResultBuffer(t *testing.T) {
+	g := New[int](WithNoCancelOnSuccess(), WithResultBuffer(2))
+	for i := 0; i < 5; i++ {
+		i := i
+		g.Go(func() (int, error) { return i, nil })
+	}
+	deadline := time.Now().Add(time.Second)
+	for g.Snapshot().Running != 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := g.Snapshot().Running; got != 3 {
+		t.Errorf("got %d blocked functions, want 3", got)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		g.Wait()
+	}()
+	got := make(map[int]bool)
+	for out := range g.Results() {
+		time.Sleep(time.Millisecond)
+		got[out.Value] = true
+	}
+	<-done
+	if len(got) != 5 {
+		t.Errorf("got %v, want 5 outcomes", got)
+	}
+}
+
+func TestWithResultBuffer_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g, _ := WithContext[int](ctx, WithNoCancelOnSuccess(), WithResultBuffer(1))
+	for i := 0; i < 3; i++ {
+		i := i
+		g.Go(func() (int, error) { return i, nil })
+	}
+	cancel()
+	g.Wait()
+	if got := len(g.Results()); got > 1 {
+		t.Errorf("got %d buffered outcomes, want at most 1", got)
+	}
+}
+
+type ctxKey string
+
+func TestWithContextPropagation(t *testing.T) {
+	tests := []struct {
+		opts []Option
+		want any
+	}{
+		{want: nil},
+		{opts: []Option{WithContextPropagation(ctxKey("token"), ctxKey("missing"))}, want: "secret"},
+	}
+	for _, tc := range tests {
+		parent := context.WithValue(context.Background(), ctxKey("token"), "secret")
+		// The spanner replaces the goroutine's context, dropping its values.
+		spanner := WithSpanner(func(ctx context.Context, index int, name string) (context.Context, func()) {
+			return context.Background(), func() {}
+		})
+		g, _ := WithContext[any](parent, append(tc.opts, spanner)...)
+		g.GoCtx(func(ctx context.Context) (any, error) {
+			if ctx.Value(ctxKey("missing")) != nil {
+				t.Error("want no value for the missing key")
+			}
+			return ctx.Value(ctxKey("token")), nil
+		})
+		if got, _ := g.Wait(); got != tc.want {
+			t.Errorf("got %v, want %v", got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+module github.com/erni27/okgroup/otel
+
+go 1.23
+
+require (
+	github.com/erni27/okgroup v0.0.0-20261016005621-48ceb84c172e
+	go.opentelemetry.io/otel/trace v1.16.0
+)
+
+require go.opentelemetry.io/otel v1.16.0 // indirect
+
+// The module is developed along with okgroup, consumers resolve the required version.
+replace github.com/erni27/okgroup => ../
@@ -0,0 +1,35 @@
+// Package otel provides OpenTelemetry tracing for okgroup.
+//
+// It is a separate module, so okgroup itself does not depend on OpenTelemetry.
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erni27/okgroup"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTracer configures the group to run each goroutine under a child span
+// of the span carried by the group's context.
+//
+// The span is named after the goroutine's name, if it was given by GoNamed or GoKeyed,
+// otherwise after the goroutine's index. The span is ended once
+// the goroutine's function returns. Functions passed to GoCtx or GoHedge
+// receive a context carrying the span.
+func WithTracer(tracer trace.Tracer) okgroup.Option {
+	return okgroup.WithSpanner(func(ctx context.Context, index int, name string) (context.Context, func()) {
+		ctx, span := tracer.Start(ctx, SpanName(index, name))
+		return ctx, func() { span.End() }
+	})
+}
+
+// SpanName returns the name of the span of the goroutine with the given index and name.
+// The name takes precedence over the index if it is not empty.
+func SpanName(index int, name string) string {
+	if name != "" {
+		return fmt.Sprintf("okgroup.goroutine[%s]", name)
+	}
+	return fmt.Sprintf("okgroup.goroutine[%d]", index)
+}
@@ -0,0 +1,68 @@
+package otel
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/erni27/okgroup"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type recordingTracer struct {
+	trace.Tracer
+	mu    sync.Mutex
+	spans map[string]*recordingSpan
+}
+
+type recordingSpan struct {
+	trace.Span
+	ended bool
+}
+
+func (s *recordingSpan) End(...trace.SpanEndOption) {
+	s.ended = true
+}
+
+type spanKey struct{}
+
+func (tr *recordingTracer) Start(ctx context.Context, name string, _ ...trace.SpanStartOption) (context.Context, trace.Span) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	span := &recordingSpan{}
+	tr.spans[name] = span
+	return context.WithValue(ctx, spanKey{}, name), span
+}
+
+func TestWithTracer(t *testing.T) {
+	tracer := &recordingTracer{spans: make(map[string]*recordingSpan)}
+	g := okgroup.New[string](WithTracer(tracer))
+	g.GoCtx(func(ctx context.Context) (string, error) {
+		name, _ := ctx.Value(spanKey{}).(string)
+		return name, nil
+	})
+	got, err := g.Wait()
+	if err != nil {
+		t.Fatalf("got err %v, want nil", err)
+	}
+	if want := SpanName(0, ""); got != want {
+		t.Errorf("got span %q, want %q", got, want)
+	}
+	span, ok := tracer.spans[SpanName(0, "")]
+	if !ok || !span.ended {
+		t.Errorf("got span %v, want ended span", span)
+	}
+}
+
+func TestWithTracer_Named(t *testing.T) {
+	tracer := &recordingTracer{spans: make(map[string]*recordingSpan)}
+	g := okgroup.New[string](WithTracer(tracer), okgroup.WithNoCancelOnSuccess())
+	g.GoNamed("primary", func() (string, error) { return "primary", nil })
+	g.GoKeyed("user:1", func() (string, error) { return "user", nil })
+	g.WaitAll()
+	for _, want := range []string{"okgroup.goroutine[primary]", "okgroup.goroutine[user:1]"} {
+		if span, ok := tracer.spans[want]; !ok || !span.ended {
+			t.Errorf("got span %v for %q, want ended span", span, want)
+		}
+	}
+}